@@ -24,14 +24,309 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/extract": {
+            "post": {
+                "description": "Copies the selected pages of an uploaded or previously generated PDF into a new document and saves it to storage (S3 or local).",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pdf"
+                ],
+                "summary": "Extract pages from a PDF",
+                "parameters": [
+                    {
+                        "description": "Extraction parameters (JSON requests)",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.ExtractRequest"
+                        }
+                    },
+                    {
+                        "type": "file",
+                        "description": "PDF to extract pages from (multipart requests)",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "URL or storage key of a previously generated PDF",
+                        "name": "source",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Pages to keep, e.g. 1-3, 7 or odd",
+                        "name": "pages",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Output validation: off, warn (default) or fail",
+                        "name": "validation",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "URL of the extracted document",
+                        "schema": {
+                            "$ref": "#/definitions/api.SplitResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Output PDF failed validation",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/form/fields": {
+            "post": {
+                "description": "Lists the fillable fields of an uploaded or stored PDF form, with their type, current value and options, as accepted by /form/fill.",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pdf"
+                ],
+                "summary": "List the fields of a PDF form",
+                "parameters": [
+                    {
+                        "description": "Form to inspect (JSON requests)",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.FormFieldsRequest"
+                        }
+                    },
+                    {
+                        "type": "file",
+                        "description": "PDF form (multipart requests)",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "URL or storage key of a stored PDF form",
+                        "name": "source",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Fields of the form, in page order",
+                        "schema": {
+                            "$ref": "#/definitions/api.FormFieldsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/form/fill": {
+            "post": {
+                "description": "Fills the fields of an uploaded or stored PDF form with the given values, optionally flattening them into the pages, and saves the result to storage (S3 or local).",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pdf"
+                ],
+                "summary": "Fill a PDF form",
+                "parameters": [
+                    {
+                        "description": "Fill parameters (JSON requests)",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.FillFormRequest"
+                        }
+                    },
+                    {
+                        "type": "file",
+                        "description": "PDF form (multipart requests)",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "URL or storage key of a stored PDF form",
+                        "name": "source",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Field values as a JSON object",
+                        "name": "values",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Draw the fields into the pages and remove the form",
+                        "name": "flatten",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Output validation: off, warn (default) or fail",
+                        "name": "validation",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "URL of the filled PDF",
+                        "schema": {
+                            "$ref": "#/definitions/api.FillFormResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Output PDF failed validation",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/generate": {
             "post": {
-                "description": "Converts a list of URLs to PDF and merges them into a single document.",
+                "description": "Converts a list of URLs to PDF, merges them, and saves to storage (S3 or local).",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/pdf"
+                    "application/json"
                 ],
                 "tags": [
                     "pdf"
@@ -39,7 +334,7 @@ const docTemplate = `{
                 "summary": "Generate PDF from URLs",
                 "parameters": [
                     {
-                        "description": "List of URLs to convert",
+                        "description": "URLs and sources to convert",
                         "name": "request",
                         "in": "body",
                         "required": true,
@@ -50,9 +345,9 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "document.pdf",
+                        "description": "URL of the generated PDF",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/api.GenerateResponse"
                         }
                     },
                     "400": {
@@ -64,6 +359,13 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "422": {
+                        "description": "Generated PDF failed validation",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -75,21 +377,1650 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.GenerateRequest": {
-            "type": "object",
-            "required": [
-                "urls"
-            ],
-            "properties": {
-                "urls": {
-                    "type": "array",
-                    "minItems": 1,
-                    "items": {
-                        "type": "string"
+        },
+        "/generate/upload": {
+            "post": {
+                "description": "Converts uploaded HTML (plain or zipped with assets), PNG/JPEG images and PDFs, merges them in order, and saves to storage (S3 or local).",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pdf"
+                ],
+                "summary": "Generate PDF from uploaded files",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Source files, in merge order",
+                        "name": "files",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Bookmark title for each file, in the same order",
+                        "name": "labels",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Print each source on one continuous page",
+                        "name": "single_page",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Browser locale, e.g. pt-BR",
+                        "name": "locale",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Browser timezone, e.g. America/Sao_Paulo",
+                        "name": "timezone_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Accept-Language header (defaults to locale)",
+                        "name": "accept_language",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cover page settings as a JSON object",
+                        "name": "cover",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Prepend a table of contents",
+                        "name": "toc",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Heading of the table of contents",
+                        "name": "toc_title",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Emulated geolocation (latitude, longitude, accuracy) as a JSON object",
+                        "name": "geolocation",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Uniform paper size settings as a JSON object",
+                        "name": "page_layout",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Start every source on a right-hand page",
+                        "name": "duplex",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Separator pages settings as a JSON object",
+                        "name": "separators",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Page numbering settings as a JSON object",
+                        "name": "page_numbers",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Watermarks, each as a JSON object",
+                        "name": "watermarks",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "N-up or booklet imposition settings as a JSON object",
+                        "name": "imposition",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Print settings (preset, omit_background, grayscale, strip_links) as a JSON object",
+                        "name": "print",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provenance attachments settings as a JSON object",
+                        "name": "attachments",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Files to attach to the generated PDF",
+                        "name": "attachment_files",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Output compression: none, standard or aggressive",
+                        "name": "compression",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Resolution images are downsampled to when compressing",
+                        "name": "image_dpi",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "AES-256 encryption settings (user_password, owner_password, allow_*) as a JSON object",
+                        "name": "encryption",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Make the PDF conform to PDF/A-2b (not with encryption or custom properties)",
+                        "name": "pdfa",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Document information (title, author, subject, keywords, creator, producer, language, properties) as a JSON object",
+                        "name": "metadata",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Digital signature settings as a JSON object",
+                        "name": "signature",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Describe the generated PDF in the response",
+                        "name": "inspect",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Output validation: off, warn (default) or fail",
+                        "name": "validation",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "URL of the generated PDF",
+                        "schema": {
+                            "$ref": "#/definitions/api.GenerateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Generated PDF failed validation",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/impose": {
+            "post": {
+                "description": "Lays the pages of an uploaded or previously generated PDF out 2, 4 or 9 to a sheet, or reorders them into a saddle-stitched booklet, and saves the result to storage (S3 or local). Bookmarks and links are dropped.",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pdf"
+                ],
+                "summary": "Impose a PDF",
+                "parameters": [
+                    {
+                        "description": "Imposition parameters (JSON requests)",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.ImposeRequest"
+                        }
+                    },
+                    {
+                        "type": "file",
+                        "description": "PDF to impose (multipart requests)",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "URL or storage key of a previously generated PDF",
+                        "name": "source",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "2-up",
+                            "4-up",
+                            "9-up",
+                            "booklet"
+                        ],
+                        "type": "string",
+                        "description": "Imposition mode",
+                        "name": "mode",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sheet size, e.g. A4 or Letter (default A4)",
+                        "name": "paper_size",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Turn the sheets sideways",
+                        "name": "landscape",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Frame every page on the sheet",
+                        "name": "border",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Space around every page on the sheet in points",
+                        "name": "margin",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Output validation: off, warn (default) or fail",
+                        "name": "validation",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "URL of the imposed PDF",
+                        "schema": {
+                            "$ref": "#/definitions/api.ImposeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Output PDF failed validation",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/inspect": {
+            "post": {
+                "description": "Reports the version, page count and sizes, metadata, fonts, encryption, attachments, bookmarks and validation errors of an uploaded or stored PDF, without modifying it.",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pdf"
+                ],
+                "summary": "Inspect a PDF",
+                "parameters": [
+                    {
+                        "description": "PDF to inspect (JSON requests)",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.InspectRequest"
+                        }
+                    },
+                    {
+                        "type": "file",
+                        "description": "PDF to inspect (multipart requests)",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "URL or storage key of a stored PDF",
+                        "name": "source",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Password of an encrypted PDF",
+                        "name": "password",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Description of the PDF",
+                        "schema": {
+                            "$ref": "#/definitions/api.Inspection"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/split": {
+            "post": {
+                "description": "Splits an uploaded or previously generated PDF by page ranges, every N pages or by top-level bookmarks, and saves every part to storage (S3 or local).",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pdf"
+                ],
+                "summary": "Split a PDF",
+                "parameters": [
+                    {
+                        "description": "Split parameters (JSON requests)",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.SplitRequest"
+                        }
+                    },
+                    {
+                        "type": "file",
+                        "description": "PDF to split (multipart requests)",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "URL or storage key of a previously generated PDF",
+                        "name": "source",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Page ranges, one document each, e.g. 1-3",
+                        "name": "ranges",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Split into documents of this many pages",
+                        "name": "every",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Split at every top-level bookmark",
+                        "name": "bookmarks",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Output validation: off, warn (default) or fail",
+                        "name": "validation",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "URLs of the parts, in order",
+                        "schema": {
+                            "$ref": "#/definitions/api.SplitResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Output PDF failed validation",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.AttachmentFile": {
+            "type": "object",
+            "required": [
+                "data",
+                "name"
+            ],
+            "properties": {
+                "data": {
+                    "description": "Data is the base64-encoded file content.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.AttachmentInfo": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.Attachments": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "description": "Files are attached as is.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.AttachmentFile"
+                    }
+                },
+                "request": {
+                    "description": "Request attaches request.json, the request with passwords redacted.",
+                    "type": "boolean"
+                },
+                "snapshots": {
+                    "description": "Snapshots attaches the HTML of every page as rendered by Chrome.",
+                    "type": "boolean"
+                },
+                "sources": {
+                    "description": "Sources attaches sources.txt, listing every source URL in merge order.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.Bookmark": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Bookmark"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.ConformanceCheck": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "passed": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.Cover": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "date": {
+                    "description": "Date is printed as is. Defaults to today's date.",
+                    "type": "string"
+                },
+                "logo": {
+                    "description": "Logo is a base64-encoded PNG, JPEG, GIF, WebP or SVG image.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "subtitle": {
+                    "type": "string"
+                },
+                "template": {
+                    "description": "Template is a custom HTML template that may use {{.Title}},\n{{.Subtitle}}, {{.Author}}, {{.Date}} and {{.Logo}}, a data URL.",
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.Encryption": {
+            "type": "object",
+            "properties": {
+                "allow_annotate": {
+                    "type": "boolean"
+                },
+                "allow_copy": {
+                    "type": "boolean"
+                },
+                "allow_modify": {
+                    "type": "boolean"
+                },
+                "allow_print": {
+                    "type": "boolean"
+                },
+                "owner_password": {
+                    "type": "string"
+                },
+                "user_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.ExtractRequest": {
+            "type": "object",
+            "required": [
+                "pages"
+            ],
+            "properties": {
+                "pages": {
+                    "description": "Pages selects the pages to keep, e.g. [\"1-3\", \"7\", \"odd\"].",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "source": {
+                    "description": "Source is the URL or storage key of a previously generated PDF.",
+                    "type": "string"
+                },
+                "validation": {
+                    "description": "Validation checks the output against the specification: \"off\",\n\"warn\" (default) reports problems in the response and \"fail\" rejects\nthe request.",
+                    "type": "string",
+                    "enum": [
+                        "off",
+                        "warn",
+                        "fail"
+                    ]
+                }
+            }
+        },
+        "api.FillFormRequest": {
+            "type": "object",
+            "properties": {
+                "flatten": {
+                    "description": "Flatten draws the fields into the pages and removes the form, so the\nresult can no longer be edited.",
+                    "type": "boolean"
+                },
+                "source": {
+                    "description": "Source is the URL or storage key of a stored PDF form.",
+                    "type": "string"
+                },
+                "validation": {
+                    "description": "Validation checks the output against the specification: \"off\",\n\"warn\" (default) reports problems in the response and \"fail\" rejects\nthe request.",
+                    "type": "string",
+                    "enum": [
+                        "off",
+                        "warn",
+                        "fail"
+                    ]
+                },
+                "values": {
+                    "description": "Values maps field names to their new value: a string for text and date\nfields, radio buttons and combo boxes, a boolean for check boxes and a\nstring or a list of strings for list boxes. Multipart requests send it\nas a JSON object.",
+                    "type": "object",
+                    "additionalProperties": {}
+                }
+            }
+        },
+        "api.FillFormResponse": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string"
+                },
+                "validation_errors": {
+                    "description": "ValidationErrors lists where the filled PDF breaks the PDF\nspecification.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.Font": {
+            "type": "object",
+            "properties": {
+                "embedded": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "subset": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.FormField": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "locked": {
+                    "type": "boolean"
+                },
+                "multiple": {
+                    "description": "Multiple tells whether a list box accepts several options.",
+                    "type": "boolean"
+                },
+                "name": {
+                    "description": "Name is the key the field is filled by.",
+                    "type": "string"
+                },
+                "options": {
+                    "description": "Options lists the choices of radio buttons, combo and list boxes.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "pages": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "type": {
+                    "description": "Type is one of text, date, checkbox, radio, combobox or listbox.",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Value is the current value, \"true\" or \"false\" for check boxes.",
+                    "type": "string"
+                }
+            }
+        },
+        "api.FormFieldsRequest": {
+            "type": "object",
+            "properties": {
+                "source": {
+                    "description": "Source is the URL or storage key of a stored PDF form.",
+                    "type": "string"
+                }
+            }
+        },
+        "api.FormFieldsResponse": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.FormField"
+                    }
+                }
+            }
+        },
+        "api.GenerateRequest": {
+            "type": "object",
+            "properties": {
+                "accept_language": {
+                    "description": "AcceptLanguage sets the Accept-Language header. Defaults to Locale.",
+                    "type": "string"
+                },
+                "attachments": {
+                    "description": "Attachments embeds provenance files, such as the source URLs and the\nrequest itself, into the generated PDF. Multipart requests send it as\na JSON object in an \"attachments\" field.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.Attachments"
+                        }
+                    ]
+                },
+                "compression": {
+                    "description": "Compression shrinks the output: \"none\" (default), \"standard\" or\n\"aggressive\".",
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "standard",
+                        "aggressive"
+                    ]
+                },
+                "cover": {
+                    "description": "Cover prepends a cover page. Multipart requests send it as a JSON\nobject in a \"cover\" field.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.Cover"
+                        }
+                    ]
+                },
+                "duplex": {
+                    "description": "Duplex inserts blank pages so every source starts on a right-hand\npage when printed double-sided.",
+                    "type": "boolean"
+                },
+                "encryption": {
+                    "description": "Encryption protects the generated PDF with AES-256. Multipart\nrequests send it as a JSON object in an \"encryption\" field.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.Encryption"
+                        }
+                    ]
+                },
+                "geolocation": {
+                    "description": "Geolocation is the position reported to pages using the Geolocation\nAPI. Multipart requests send it as a JSON object in a \"geolocation\"\nfield.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.Geolocation"
+                        }
+                    ]
+                },
+                "image_dpi": {
+                    "description": "ImageDPI overrides the resolution images are downsampled to when\ncompressing.",
+                    "type": "integer",
+                    "maximum": 1200,
+                    "minimum": 36
+                },
+                "imposition": {
+                    "description": "Imposition lays several pages out on each sheet (\"2-up\", \"4-up\",\n\"9-up\") or turns the document into a booklet. Multipart requests send\nit as a JSON object in an \"imposition\" field.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.Imposition"
+                        }
+                    ]
+                },
+                "inspect": {
+                    "description": "Inspect adds a description of the generated PDF, as returned by\n/inspect, to the response.",
+                    "type": "boolean"
+                },
+                "locale": {
+                    "description": "Locale sets the browser locale for date, number and currency\nformatting, e.g. \"pt-BR\".",
+                    "type": "string"
+                },
+                "metadata": {
+                    "description": "Metadata sets the document information of the generated PDF.\nMultipart requests send it as a JSON object in a \"metadata\" field.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.Metadata"
+                        }
+                    ]
+                },
+                "page_layout": {
+                    "description": "PageLayout scales every page of the merged document to the same\npaper size. Multipart requests send it as a JSON object in a\n\"page_layout\" field.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.PageLayout"
+                        }
+                    ]
+                },
+                "page_numbers": {
+                    "description": "PageNumbers stamps continuous page numbers across the merged\ndocument. Multipart requests send it as a JSON object in a\n\"page_numbers\" field.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.PageNumbers"
+                        }
+                    ]
+                },
+                "pdfa": {
+                    "description": "PDFA makes the generated PDF conform to PDF/A-2b for archival. It\ncannot be combined with encryption or custom metadata properties.",
+                    "type": "boolean"
+                },
+                "print": {
+                    "description": "Print adjusts the generated PDF for printing on paper, e.g. in\ngrayscale and without backgrounds. Multipart requests send it as a\nJSON object in a \"print\" field.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.Print"
+                        }
+                    ]
+                },
+                "separators": {
+                    "description": "Separators inserts a page between consecutive sources. Multipart\nrequests send it as a JSON object in a \"separators\" field.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.Separators"
+                        }
+                    ]
+                },
+                "signature": {
+                    "description": "Signature digitally signs the generated PDF with the server\ncertificate. Multipart requests send it as a JSON object in a\n\"signature\" field.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.Signature"
+                        }
+                    ]
+                },
+                "single_page": {
+                    "description": "SinglePage prints every source on one continuous page as tall as the\ndocument instead of A4 pages.",
+                    "type": "boolean"
+                },
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.SourceRequest"
+                    }
+                },
+                "timezone_id": {
+                    "description": "TimezoneID sets the browser timezone, e.g. \"America/Sao_Paulo\".",
+                    "type": "string"
+                },
+                "toc": {
+                    "description": "TOC prepends a table of contents listing every source with its\nstarting page.",
+                    "type": "boolean"
+                },
+                "toc_title": {
+                    "description": "TOCTitle is the heading of the table of contents.",
+                    "type": "string"
+                },
+                "urls": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "validation": {
+                    "description": "Validation checks the generated PDF against the specification:\n\"off\", \"warn\" (default) reports problems in the response and \"fail\"\nrejects the document.",
+                    "type": "string",
+                    "enum": [
+                        "off",
+                        "warn",
+                        "fail"
+                    ]
+                },
+                "watermarks": {
+                    "description": "Watermarks are text or image marks applied to the merged document.\nMultipart requests send each one as a JSON object in a \"watermarks\"\nfield.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Watermark"
+                    }
+                }
+            }
+        },
+        "api.GenerateResponse": {
+            "type": "object",
+            "properties": {
+                "conformance": {
+                    "description": "Conformance reports every PDF/A check when the request asked for\nPDF/A.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ConformanceCheck"
+                    }
+                },
+                "inspection": {
+                    "description": "Inspection describes the generated PDF when the request asked for it.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/api.Inspection"
+                        }
+                    ]
+                },
+                "size_after": {
+                    "type": "integer"
+                },
+                "size_before": {
+                    "description": "SizeBefore and SizeAfter are the document sizes in bytes before and\nafter compression.",
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "validation_errors": {
+                    "description": "ValidationErrors lists where the generated PDF breaks the PDF\nspecification.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.Geolocation": {
+            "type": "object",
+            "properties": {
+                "accuracy": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "latitude": {
+                    "type": "number",
+                    "maximum": 90,
+                    "minimum": -90
+                },
+                "longitude": {
+                    "type": "number",
+                    "maximum": 180,
+                    "minimum": -180
+                }
+            }
+        },
+        "api.ImposeRequest": {
+            "type": "object",
+            "required": [
+                "mode"
+            ],
+            "properties": {
+                "border": {
+                    "description": "Border frames every page on the sheet.",
+                    "type": "boolean"
+                },
+                "landscape": {
+                    "type": "boolean"
+                },
+                "margin": {
+                    "description": "Margin is the space around every page on the sheet in points.",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "2-up",
+                        "4-up",
+                        "9-up",
+                        "booklet"
+                    ]
+                },
+                "paper_size": {
+                    "description": "PaperSize is the sheet size, such as \"A4\" or \"Letter\". Defaults to \"A4\".",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "Source is the URL or storage key of a previously generated PDF.",
+                    "type": "string"
+                },
+                "validation": {
+                    "description": "Validation checks the output against the specification: \"off\",\n\"warn\" (default) reports problems in the response and \"fail\" rejects\nthe request.",
+                    "type": "string",
+                    "enum": [
+                        "off",
+                        "warn",
+                        "fail"
+                    ]
+                }
+            }
+        },
+        "api.ImposeResponse": {
+            "type": "object",
+            "properties": {
+                "url": {
+                    "type": "string"
+                },
+                "validation_errors": {
+                    "description": "ValidationErrors lists where the imposed PDF breaks the PDF\nspecification.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.Imposition": {
+            "type": "object",
+            "required": [
+                "mode"
+            ],
+            "properties": {
+                "border": {
+                    "description": "Border frames every page on the sheet.",
+                    "type": "boolean"
+                },
+                "landscape": {
+                    "type": "boolean"
+                },
+                "margin": {
+                    "description": "Margin is the space around every page on the sheet in points.",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "2-up",
+                        "4-up",
+                        "9-up",
+                        "booklet"
+                    ]
+                },
+                "paper_size": {
+                    "description": "PaperSize is the sheet size, such as \"A4\" or \"Letter\". Defaults to \"A4\".",
+                    "type": "string"
+                }
+            }
+        },
+        "api.InspectRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "description": "Password opens encrypted PDFs.",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "Source is the URL or storage key of a stored PDF.",
+                    "type": "string"
+                }
+            }
+        },
+        "api.Inspection": {
+            "type": "object",
+            "properties": {
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.AttachmentInfo"
+                    }
+                },
+                "bookmarks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Bookmark"
+                    }
+                },
+                "creation_date": {
+                    "description": "CreationDate and ModDate are raw PDF dates, e.g.\n\"D:20240131120000+00'00'\".",
+                    "type": "string"
+                },
+                "encrypted": {
+                    "type": "boolean"
+                },
+                "fonts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.Font"
+                    }
+                },
+                "form": {
+                    "type": "boolean"
+                },
+                "metadata": {
+                    "$ref": "#/definitions/api.Metadata"
+                },
+                "mod_date": {
+                    "type": "string"
+                },
+                "page_sizes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.PageSize"
+                    }
+                },
+                "pages": {
+                    "type": "integer"
+                },
+                "permissions": {
+                    "description": "Permissions lists what an encrypted PDF allows, e.g. \"print\" or\n\"copy\". It is null for PDFs that are not encrypted.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "signed": {
+                    "type": "boolean"
+                },
+                "tagged": {
+                    "type": "boolean"
+                },
+                "valid": {
+                    "description": "Valid is false when the PDF breaks the specification, as described by\nValidationErrors.",
+                    "type": "boolean"
+                },
+                "validation_errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.Metadata": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "creator": {
+                    "type": "string"
+                },
+                "keywords": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "language": {
+                    "description": "Language is a BCP 47 tag such as \"pt-BR\".",
+                    "type": "string"
+                },
+                "producer": {
+                    "type": "string"
+                },
+                "properties": {
+                    "description": "Properties are custom info dictionary entries.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.PageLayout": {
+            "type": "object",
+            "properties": {
+                "auto_rotate": {
+                    "description": "AutoRotate turns pages whose orientation differs from the paper's.",
+                    "type": "boolean"
+                },
+                "landscape": {
+                    "type": "boolean"
+                },
+                "margin": {
+                    "description": "Margin is the blank border on every edge of the paper in points.",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "paper_size": {
+                    "description": "PaperSize is a paper name such as \"A4\" or \"Letter\". Defaults to \"A4\".",
+                    "type": "string"
+                },
+                "scale": {
+                    "description": "Scale is \"fit\" (default), which keeps the whole page visible, or\n\"fill\", which covers the paper and crops the overflow.",
+                    "type": "string",
+                    "enum": [
+                        "fit",
+                        "fill"
+                    ]
+                }
+            }
+        },
+        "api.PageNumbers": {
+            "type": "object",
+            "properties": {
+                "color": {
+                    "type": "string"
+                },
+                "font": {
+                    "type": "string"
+                },
+                "font_size": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "format": {
+                    "description": "Format may use {page} and {total}. Defaults to \"Page {page} of {total}\".",
+                    "type": "string"
+                },
+                "margin": {
+                    "description": "Margin is the distance from the page edge in points.",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "position": {
+                    "type": "string",
+                    "enum": [
+                        "tl",
+                        "tc",
+                        "tr",
+                        "l",
+                        "c",
+                        "r",
+                        "bl",
+                        "bc",
+                        "br"
+                    ]
+                },
+                "skip": {
+                    "description": "Skip leaves the first pages unnumbered.",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "skip_front_matter": {
+                    "description": "SkipFrontMatter leaves the cover and table of contents unnumbered.",
+                    "type": "boolean"
+                },
+                "start": {
+                    "description": "Start is the number of the first numbered page. Defaults to 1.",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "api.PageSize": {
+            "type": "object",
+            "properties": {
+                "height": {
+                    "type": "number"
+                },
+                "pages": {
+                    "description": "Pages lists the pages of this size, e.g. \"1-3,7\".",
+                    "type": "string"
+                },
+                "paper": {
+                    "description": "Paper is the matching paper name, such as \"A4\", if any.",
+                    "type": "string"
+                },
+                "width": {
+                    "type": "number"
+                }
+            }
+        },
+        "api.Print": {
+            "type": "object",
+            "properties": {
+                "grayscale": {
+                    "description": "Grayscale renders every page in shades of gray.",
+                    "type": "boolean"
+                },
+                "omit_background": {
+                    "description": "OmitBackground prints web pages without background colors and\nimages.",
+                    "type": "boolean"
+                },
+                "preset": {
+                    "description": "Preset is \"toner_saver\", which turns on every setting below, or\n\"grayscale\".",
+                    "type": "string",
+                    "enum": [
+                        "toner_saver",
+                        "grayscale"
+                    ]
+                },
+                "strip_links": {
+                    "description": "StripLinks prints links like plain text and removes them from the\nPDF.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.Separators": {
+            "type": "object",
+            "properties": {
+                "template": {
+                    "description": "Template is a custom HTML template for titled separators that may use\n{{.Title}}, {{.Number}} and {{.Total}}. It implies Titled.",
+                    "type": "string"
+                },
+                "titled": {
+                    "description": "Titled announces the title of the next source on the separator page.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.Signature": {
+            "type": "object",
+            "properties": {
+                "contact_info": {
+                    "type": "string"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "page": {
+                    "description": "Page holds the visible signature. Defaults to the last page.",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "rect": {
+                    "description": "Rect is the box of a visible signature as [x1, y1, x2, y2] in points\nfrom the bottom left corner. Defaults to the bottom right corner.",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "visible": {
+                    "description": "Visible draws a box with the signer, date, reason and location.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "api.SourceRequest": {
+            "type": "object",
+            "required": [
+                "type"
+            ],
+            "properties": {
+                "label": {
+                    "description": "Label is the bookmark title of the source in the merged PDF. Defaults\nto the page \u003ctitle\u003e.",
+                    "type": "string"
+                },
+                "markdown": {
+                    "description": "Markdown is the GitHub Flavored Markdown document when Type is \"markdown\".",
+                    "type": "string"
+                },
+                "stylesheet": {
+                    "description": "Stylesheet is custom CSS applied on top of the Markdown theme.",
+                    "type": "string"
+                },
+                "theme": {
+                    "description": "Theme is the built-in stylesheet for Markdown: github, minimal or serif.",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Type selects how the source is rendered: \"url\" or \"markdown\".",
+                    "type": "string",
+                    "enum": [
+                        "url",
+                        "markdown"
+                    ]
+                },
+                "url": {
+                    "description": "URL is the page to convert when Type is \"url\".",
+                    "type": "string"
+                }
+            }
+        },
+        "api.SplitFile": {
+            "type": "object",
+            "properties": {
+                "pages": {
+                    "description": "Pages lists the pages of the original PDF the document holds.",
+                    "type": "string"
+                },
+                "title": {
+                    "description": "Title is the bookmark the document was split at, if any.",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "validation_errors": {
+                    "description": "ValidationErrors lists where the document breaks the PDF\nspecification.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api.SplitRequest": {
+            "type": "object",
+            "properties": {
+                "bookmarks": {
+                    "description": "Bookmarks starts a new document at every top-level bookmark.",
+                    "type": "boolean"
+                },
+                "every": {
+                    "description": "Every splits the PDF into documents of this many pages.",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "ranges": {
+                    "description": "Ranges become one document each, e.g. [\"1-3\", \"4,6-8\"].",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "source": {
+                    "description": "Source is the URL or storage key of a previously generated PDF.",
+                    "type": "string"
+                },
+                "validation": {
+                    "description": "Validation checks the output against the specification: \"off\",\n\"warn\" (default) reports problems in the response and \"fail\" rejects\nthe request.",
+                    "type": "string",
+                    "enum": [
+                        "off",
+                        "warn",
+                        "fail"
+                    ]
+                }
+            }
+        },
+        "api.SplitResponse": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.SplitFile"
+                    }
+                }
+            }
+        },
+        "api.Watermark": {
+            "type": "object",
+            "properties": {
+                "color": {
+                    "type": "string"
+                },
+                "font": {
+                    "type": "string"
+                },
+                "font_size": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "image": {
+                    "description": "Image is a base64-encoded PNG or JPEG.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
                     }
+                },
+                "offset_x": {
+                    "type": "number"
+                },
+                "offset_y": {
+                    "type": "number"
+                },
+                "opacity": {
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "pages": {
+                    "description": "Pages selects the pages to mark, e.g. \"1-3,odd\". Defaults to all.",
+                    "type": "string"
+                },
+                "position": {
+                    "type": "string",
+                    "enum": [
+                        "tl",
+                        "tc",
+                        "tr",
+                        "l",
+                        "c",
+                        "r",
+                        "bl",
+                        "bc",
+                        "br"
+                    ]
+                },
+                "rotation": {
+                    "description": "Rotation in degrees; when omitted the mark follows the page diagonal.",
+                    "type": "number",
+                    "maximum": 180,
+                    "minimum": -180
+                },
+                "scale": {
+                    "description": "Scale is the mark width relative to the page width.",
+                    "type": "number",
+                    "maximum": 1,
+                    "minimum": 0
+                },
+                "stamp": {
+                    "description": "Stamp draws the mark over the page content instead of behind it.",
+                    "type": "boolean"
+                },
+                "text": {
+                    "description": "Text may use %p and %P for the page number and page count.",
+                    "type": "string"
                 }
             }
         }
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// maxArchiveFiles caps the number of entries extracted from a ZIP upload.
+	maxArchiveFiles = 1000
+	// maxArchiveBytes caps the total uncompressed size of a ZIP upload to
+	// protect the host against zip bombs.
+	maxArchiveBytes = 200 << 20
+)
+
+// extractZip unpacks the archive at src into dest. Entries with absolute or
+// escaping paths and symlinks are rejected so the archive can never write
+// outside of dest.
+func extractZip(src, dest string) error {
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer zr.Close()
+
+	if len(zr.File) > maxArchiveFiles {
+		return fmt.Errorf("archive has too many files, max is %d", maxArchiveFiles)
+	}
+
+	var total int64
+	for _, f := range zr.File {
+		if !filepath.IsLocal(f.Name) {
+			return fmt.Errorf("archive entry %q escapes the archive root", f.Name)
+		}
+
+		target := filepath.Join(dest, f.Name)
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to create %s: %w", f.Name, err)
+			}
+			continue
+		}
+
+		if !f.Mode().IsRegular() {
+			return fmt.Errorf("archive entry %q is not a regular file", f.Name)
+		}
+
+		written, err := extractFile(f, target, maxArchiveBytes-total)
+		if err != nil {
+			return err
+		}
+		total += written
+	}
+
+	return nil
+}
+
+// extractFile writes a single archive entry to target, failing once more than
+// limit bytes have been decompressed.
+func extractFile(f *zip.File, target string, limit int64) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create dir for %s: %w", f.Name, err)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", f.Name, err)
+	}
+	defer out.Close()
+
+	written, err := io.Copy(out, io.LimitReader(rc, limit+1))
+	if err != nil {
+		return written, fmt.Errorf("failed to extract %s: %w", f.Name, err)
+	}
+	if written > limit {
+		return written, fmt.Errorf("archive is too large, max is %d MB uncompressed", maxArchiveBytes>>20)
+	}
+
+	return written, nil
+}
+
+// findEntry returns the slash-separated path of the HTML document to render
+// from an extracted archive: the shallowest index.html, or the only HTML file
+// when the archive does not have an index.
+func findEntry(root string) (string, error) {
+	var index string
+	var htmlFiles []string
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		switch strings.ToLower(filepath.Ext(rel)) {
+		case ".html", ".htm":
+			htmlFiles = append(htmlFiles, rel)
+		default:
+			return nil
+		}
+
+		if strings.EqualFold(filepath.Base(rel), "index.html") &&
+			(index == "" || strings.Count(rel, "/") < strings.Count(index, "/")) {
+			index = rel
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to scan archive: %w", err)
+	}
+
+	switch {
+	case index != "":
+		return index, nil
+	case len(htmlFiles) == 1:
+		return htmlFiles[0], nil
+	default:
+		return "", fmt.Errorf("archive must contain an index.html")
+	}
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/psilva1982/rapid_pdf/internal/merger"
+	"github.com/psilva1982/rapid_pdf/internal/pipeline"
+)
+
+const (
+	// attachmentFilesField is the multipart form field that carries files to
+	// attach to the generated PDF.
+	attachmentFilesField = "attachment_files"
+	// requestAttachment is the name of the attached request.
+	requestAttachment = "request.json"
+	// redacted replaces secrets in the attached request.
+	redacted = "[REDACTED]"
+)
+
+// secretKeys are the request fields whose values never end up in the
+// attached request.
+var secretKeys = map[string]bool{
+	"user_password":  true,
+	"owner_password": true,
+}
+
+// Attachments selects the files embedded into the generated PDF to record
+// its provenance.
+type Attachments struct {
+	// Sources attaches sources.txt, listing every source URL in merge order.
+	Sources bool `json:"sources"`
+	// Request attaches request.json, the request with passwords redacted.
+	Request bool `json:"request"`
+	// Snapshots attaches the HTML of every page as rendered by Chrome.
+	Snapshots bool `json:"snapshots"`
+	// Files are attached as is.
+	Files []AttachmentFile `json:"files" binding:"dive"`
+}
+
+// AttachmentFile is a caller-supplied file to attach.
+type AttachmentFile struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	// Data is the base64-encoded file content.
+	Data []byte `json:"data" binding:"required"`
+}
+
+// toAttachments converts the request into pipeline attachments, adding the
+// redacted request and the uploaded files.
+func (a Attachments) toAttachments(request any, uploads []merger.Attachment) (*pipeline.Attachments, error) {
+	attachments := &pipeline.Attachments{
+		Sources:   a.Sources,
+		Snapshots: a.Snapshots,
+	}
+
+	if a.Request {
+		data, err := redactRequest(request)
+		if err != nil {
+			return nil, err
+		}
+		attachments.Files = append(attachments.Files, merger.Attachment{
+			Name:        requestAttachment,
+			Description: "Request that generated the document, with secrets redacted",
+			Data:        data,
+		})
+	}
+
+	for _, f := range a.Files {
+		attachments.Files = append(attachments.Files, merger.Attachment{
+			Name:        f.Name,
+			Description: f.Description,
+			Data:        f.Data,
+		})
+	}
+
+	attachments.Files = append(attachments.Files, uploads...)
+	return attachments, nil
+}
+
+// redactRequest encodes request as indented JSON with passwords and URL
+// credentials replaced.
+func redactRequest(request any) ([]byte, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	var tree any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	data, err = json.MarshalIndent(redact(tree), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	return data, nil
+}
+
+// redact walks a decoded JSON value, replacing the values of secretKeys and
+// the passwords of URLs.
+func redact(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if secretKeys[key] {
+				if value != "" {
+					v[key] = redacted
+				}
+				continue
+			}
+			v[key] = redact(value)
+		}
+		return v
+	case []any:
+		for i, value := range v {
+			v[i] = redact(value)
+		}
+		return v
+	case string:
+		if u, err := url.Parse(v); err == nil && u.User != nil {
+			return u.Redacted()
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// readAttachments reads the files uploaded in the attachment field.
+func readAttachments(files []*multipart.FileHeader) ([]merger.Attachment, error) {
+	attachments := make([]merger.Attachment, 0, len(files))
+	for _, fh := range files {
+		f, err := fh.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open attachment %s: %w", fh.Filename, err)
+		}
+		data, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attachment %s: %w", fh.Filename, err)
+		}
+
+		attachments = append(attachments, merger.Attachment{
+			Name: filepath.Base(strings.ReplaceAll(fh.Filename, `\`, "/")),
+			Data: data,
+		})
+	}
+	return attachments, nil
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/psilva1982/rapid_pdf/internal/merger"
+)
+
+// FormFieldsRequest identifies the PDF form whose fields are listed. The PDF
+// is either uploaded in the multipart "file" field or referenced by Source.
+type FormFieldsRequest struct {
+	// Source is the URL or storage key of a stored PDF form.
+	Source string `json:"source" form:"source"`
+}
+
+// FormField describes a fillable field of a PDF form.
+type FormField struct {
+	// Name is the key the field is filled by.
+	Name string `json:"name"`
+	ID   string `json:"id"`
+	// Type is one of text, date, checkbox, radio, combobox or listbox.
+	Type  string `json:"type"`
+	Pages []int  `json:"pages"`
+	// Value is the current value, "true" or "false" for check boxes.
+	Value string `json:"value"`
+	// Options lists the choices of radio buttons, combo and list boxes.
+	Options []string `json:"options,omitempty"`
+	// Multiple tells whether a list box accepts several options.
+	Multiple bool `json:"multiple,omitempty"`
+	Locked   bool `json:"locked"`
+}
+
+// FormFieldsResponse defines the JSON response listing the fields of a form.
+type FormFieldsResponse struct {
+	Fields []FormField `json:"fields"`
+}
+
+// FillFormRequest defines the parameters of a form fill. The PDF form is
+// either uploaded in the multipart "file" field or referenced by Source.
+type FillFormRequest struct {
+	// Source is the URL or storage key of a stored PDF form.
+	Source string `json:"source" form:"source"`
+	// Values maps field names to their new value: a string for text and date
+	// fields, radio buttons and combo boxes, a boolean for check boxes and a
+	// string or a list of strings for list boxes. Multipart requests send it
+	// as a JSON object.
+	Values map[string]any `json:"values" form:"values"`
+	// Flatten draws the fields into the pages and removes the form, so the
+	// result can no longer be edited.
+	Flatten bool `json:"flatten" form:"flatten"`
+	// Validation checks the output against the specification: "off",
+	// "warn" (default) reports problems in the response and "fail" rejects
+	// the request.
+	Validation string `json:"validation" form:"validation" binding:"omitempty,oneof=off warn fail"`
+}
+
+// FillFormResponse defines the JSON response returned after a form fill.
+type FillFormResponse struct {
+	URL string `json:"url"`
+	// ValidationErrors lists where the filled PDF breaks the PDF
+	// specification.
+	ValidationErrors []string `json:"validation_errors,omitempty"`
+}
+
+// ListFormFields handles listing the fields of a PDF form.
+//
+// @Summary      List the fields of a PDF form
+// @Description  Lists the fillable fields of an uploaded or stored PDF form, with their type, current value and options, as accepted by /form/fill.
+// @Tags         pdf
+// @Accept       json,multipart/form-data
+// @Produce      json
+// @Param        request body FormFieldsRequest false "Form to inspect (JSON requests)"
+// @Param        file formData file false "PDF form (multipart requests)"
+// @Param        source formData string false "URL or storage key of a stored PDF form"
+// @Success      200 {object} FormFieldsResponse "Fields of the form, in page order"
+// @Failure      400 {object} map[string]string "Bad Request"
+// @Failure      404 {object} map[string]string "Not Found"
+// @Failure      413 {object} map[string]string "Request Entity Too Large"
+// @Failure      500 {object} map[string]string "Internal Server Error"
+// @Router       /form/fields [post]
+func (h *Handler) ListFormFields(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.Config.MaxUploadBytes())
+
+	var req FormFieldsRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.bindError(c, err)
+		return
+	}
+
+	workDir, err := os.MkdirTemp("", "rapid_pdf_form_*")
+	if err != nil {
+		slog.Error("failed to create form dir", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer os.RemoveAll(workDir)
+
+	input, ok := h.loadPDF(c, req.Source, workDir)
+	if !ok {
+		return
+	}
+
+	f, err := os.Open(input)
+	if err != nil {
+		slog.Error("failed to open form", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer f.Close()
+
+	fields, err := merger.FormFields(f)
+	if err != nil {
+		slog.Warn("listing form fields failed", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("listing form fields failed: %v", err)})
+		return
+	}
+
+	resp := FormFieldsResponse{Fields: make([]FormField, 0, len(fields))}
+	for _, field := range fields {
+		resp.Fields = append(resp.Fields, FormField{
+			Name:     field.Name,
+			ID:       field.ID,
+			Type:     field.Type,
+			Pages:    field.Pages,
+			Value:    field.Value,
+			Options:  field.Options,
+			Multiple: field.Multiple,
+			Locked:   field.Locked,
+		})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// FillForm handles filling a PDF form with JSON data.
+//
+// @Summary      Fill a PDF form
+// @Description  Fills the fields of an uploaded or stored PDF form with the given values, optionally flattening them into the pages, and saves the result to storage (S3 or local).
+// @Tags         pdf
+// @Accept       json,multipart/form-data
+// @Produce      json
+// @Param        request body FillFormRequest false "Fill parameters (JSON requests)"
+// @Param        file formData file false "PDF form (multipart requests)"
+// @Param        source formData string false "URL or storage key of a stored PDF form"
+// @Param        values formData string false "Field values as a JSON object"
+// @Param        flatten formData bool false "Draw the fields into the pages and remove the form"
+// @Param        validation formData string false "Output validation: off, warn (default) or fail"
+// @Success      200 {object} FillFormResponse "URL of the filled PDF"
+// @Failure      400 {object} map[string]string "Bad Request"
+// @Failure      404 {object} map[string]string "Not Found"
+// @Failure      413 {object} map[string]string "Request Entity Too Large"
+// @Failure      422 {object} map[string]any "Output PDF failed validation"
+// @Failure      500 {object} map[string]string "Internal Server Error"
+// @Router       /form/fill [post]
+func (h *Handler) FillForm(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.Config.MaxUploadBytes())
+
+	var req FillFormRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.bindError(c, err)
+		return
+	}
+	if len(req.Values) == 0 && !req.Flatten {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "values or flatten is required"})
+		return
+	}
+
+	workDir, err := os.MkdirTemp("", "rapid_pdf_form_*")
+	if err != nil {
+		slog.Error("failed to create form dir", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer os.RemoveAll(workDir)
+
+	input, ok := h.loadPDF(c, req.Source, workDir)
+	if !ok {
+		return
+	}
+
+	slog.Info("received form fill request", "fields", len(req.Values), "flatten", req.Flatten)
+
+	output := filepath.Join(workDir, "filled.pdf")
+	if err := fillFormFile(input, output, req.Values, req.Flatten); err != nil {
+		slog.Warn("form fill failed", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("form fill failed: %v", err)})
+		return
+	}
+
+	problems, ok := validateFile(c, output, req.Validation)
+	if !ok {
+		return
+	}
+
+	f, err := os.Open(output)
+	if err != nil {
+		slog.Error("failed to read filled form", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer f.Close()
+
+	fileURL, err := h.Storage.SaveStream(c.Request.Context(), "form.pdf", f)
+	if err != nil {
+		slog.Error("failed to save filled form to storage", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("storage failed: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, FillFormResponse{URL: fileURL, ValidationErrors: problems})
+}
+
+// fillFormFile fills the PDF form at input and writes the result to output.
+func fillFormFile(input, output string, values map[string]any, flatten bool) error {
+	in, err := os.Open(input)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := merger.FillForm(in, out, values, flatten); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -1,16 +1,17 @@
 package api
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
-	"os"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/psilva1982/rapid_pdf/internal/config"
 	"github.com/psilva1982/rapid_pdf/internal/converter"
 	"github.com/psilva1982/rapid_pdf/internal/merger"
+	"github.com/psilva1982/rapid_pdf/internal/pipeline"
 	"github.com/psilva1982/rapid_pdf/internal/storage"
 )
 
@@ -18,28 +19,65 @@ import (
 type Handler struct {
 	Config  *config.Config
 	Storage storage.Storage
+	// Signer signs documents on request. Nil when signing is not configured.
+	Signer *merger.Signer
 }
 
-// NewHandler creates a new Handler with the given configuration and storage backend.
-func NewHandler(cfg *config.Config, store storage.Storage) *Handler {
+// NewHandler creates a new Handler with the given configuration, storage
+// backend and optional signer.
+func NewHandler(cfg *config.Config, store storage.Storage, signer *merger.Signer) *Handler {
 	return &Handler{
 		Config:  cfg,
 		Storage: store,
+		Signer:  signer,
 	}
 }
 
 // GenerateRequest defines the expected JSON body for PDF generation.
+// URLs are converted first, followed by any typed sources, in order.
 type GenerateRequest struct {
-	URLs []string `json:"urls" binding:"required,min=1"`
+	URLs    []string        `json:"urls"`
+	Sources []SourceRequest `json:"sources" binding:"dive"`
+	GenerateOptions
 }
 
 // GenerateResponse defines the JSON response returned after PDF generation.
 type GenerateResponse struct {
 	URL string `json:"url"`
+	// SizeBefore and SizeAfter are the document sizes in bytes before and
+	// after compression.
+	SizeBefore int64 `json:"size_before"`
+	SizeAfter  int64 `json:"size_after"`
+	// ValidationErrors lists where the generated PDF breaks the PDF
+	// specification.
+	ValidationErrors []string `json:"validation_errors,omitempty"`
+	// Conformance reports every PDF/A check when the request asked for
+	// PDF/A.
+	Conformance []ConformanceCheck `json:"conformance,omitempty"`
+	// Inspection describes the generated PDF when the request asked for it.
+	Inspection *Inspection `json:"inspection,omitempty"`
+}
+
+// ConformanceCheck is the outcome of one PDF/A requirement, such as
+// "fonts_embedded" or "output_intent".
+type ConformanceCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// conformanceChecks converts the PDF/A checks of a pipeline report.
+func conformanceChecks(checks []merger.ConformanceCheck) []ConformanceCheck {
+	var out []ConformanceCheck
+	for _, c := range checks {
+		out = append(out, ConformanceCheck{Name: c.Name, Passed: c.Passed, Detail: c.Detail})
+	}
+	return out
 }
 
 // GeneratePDF handles the PDF generation request.
-// It accepts a JSON body with a list of URLs, converts them concurrently,
+// It accepts a JSON body with a list of URLs and typed sources (such as
+// Markdown documents), converts them,
 // merges the results, saves the PDF using the configured storage backend,
 // and returns the file URL.
 //
@@ -48,9 +86,10 @@ type GenerateResponse struct {
 // @Tags         pdf
 // @Accept       json
 // @Produce      json
-// @Param        request body GenerateRequest true "List of URLs to convert"
+// @Param        request body GenerateRequest true "URLs and sources to convert"
 // @Success      200 {object} GenerateResponse "URL of the generated PDF"
 // @Failure      400 {object} map[string]string "Bad Request"
+// @Failure      422 {object} map[string]any "Generated PDF failed validation"
 // @Failure      500 {object} map[string]string "Internal Server Error"
 // @Router       /generate [post]
 func (h *Handler) GeneratePDF(c *gin.Context) {
@@ -70,67 +109,178 @@ func (h *Handler) GeneratePDF(c *gin.Context) {
 		}
 	}
 
-	if len(req.URLs) > h.Config.MaxURLs {
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("too many URLs provided, max is %d", h.Config.MaxURLs)})
+	total := len(req.URLs) + len(req.Sources)
+	if total == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one URL or source is required"})
 		return
 	}
 
-	slog.Info("received generate request", "url_count", len(req.URLs))
-
-	// Create a temporary file for the merged PDF
-	tmpFile, err := os.CreateTemp("", "rapid_pdf_merged_*.pdf")
-	if err != nil {
-		slog.Error("failed to create temp file", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	if total > h.Config.MaxURLs {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("too many URLs provided, max is %d", h.Config.MaxURLs)})
 		return
 	}
-	// We close the file immediately as we only need the path for now; merger/converter handles IO
-	tmpFile.Close()
-	outputPath := tmpFile.Name()
 
-	// Ensure cleanup of the final merged file after serving
-	defer os.Remove(outputPath)
+	sources := converter.URLSources(req.URLs)
+	for i, sr := range req.Sources {
+		src, err := sr.toSource()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid source #%d: %v", i+1, err)})
+			return
+		}
+		sources = append(sources, src)
+	}
 
-	// Context for the request is passed down
-	ctx := c.Request.Context()
+	slog.Info("received generate request", "url_count", len(req.URLs), "source_count", len(req.Sources))
 
-	// 1. Convert all URLs to individual PDFs
-	timeout := time.Duration(h.Config.TimeoutSeconds) * time.Second
-	waitDelay := time.Duration(h.Config.PageLoadWaitSeconds) * time.Second
-	pdfFiles, err := converter.ConvertAll(ctx, req.URLs, timeout, waitDelay)
-	if err != nil {
-		slog.Error("conversion failed", "error", err)
-		// Cleanup any partial files
-		merger.Cleanup(pdfFiles)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("conversion failed: %v", err)})
+	h.generate(c, sources, req.GenerateOptions, req, nil)
+}
+
+// generate converts the given sources, merges them into a single PDF, saves it
+// using the configured storage backend and writes the JSON response.
+// request is the decoded request, attached when asked for, and uploads are
+// caller-supplied files to attach.
+func (h *Handler) generate(c *gin.Context, sources []converter.Source, opts GenerateOptions, request any, uploads []merger.Attachment) {
+	pipelineOpts := h.pipelineOptions(opts)
+	if opts.Attachments != nil || len(uploads) > 0 {
+		attachments, err := opts.attachments().toAttachments(request, uploads)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid attachments: %v", err)})
+			return
+		}
+		if err := attachments.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid attachments: %v", err)})
+			return
+		}
+		pipelineOpts.Attachments = attachments
+	}
+	if err := pipelineOpts.Render.ValidateEmulation(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := pipelineOpts.Metadata.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid metadata: %v", err)})
+		return
+	}
+	if cover := pipelineOpts.Cover; cover != nil {
+		if err := cover.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid cover: %v", err)})
+			return
+		}
+	}
+	if layout := pipelineOpts.PageLayout; layout != nil {
+		if err := layout.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid page layout: %v", err)})
+			return
+		}
+	}
+	if s := pipelineOpts.Separators; s != nil {
+		if err := s.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid separators: %v", err)})
+			return
+		}
+	}
+	if pn := pipelineOpts.PageNumbers; pn != nil {
+		if err := pn.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid page numbers: %v", err)})
+			return
+		}
+	}
+	for i, w := range pipelineOpts.Watermarks {
+		if err := w.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid watermark #%d: %v", i+1, err)})
+			return
+		}
+	}
+	if imp := pipelineOpts.Imposition; imp != nil {
+		if err := imp.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid imposition: %v", err)})
+			return
+		}
+	}
+	if p := pipelineOpts.Print; p != nil {
+		if err := p.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid print settings: %v", err)})
+			return
+		}
+	}
+	if sig := pipelineOpts.Signature; sig != nil {
+		if h.Signer == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "signing is not configured on this server"})
+			return
+		}
+		if pipelineOpts.Encryption != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "signature cannot be combined with encryption"})
+			return
+		}
+		if err := sig.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid signature: %v", err)})
+			return
+		}
+	}
+	if pipelineOpts.PDFA && pipelineOpts.Encryption != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "pdfa cannot be combined with encryption"})
+		return
+	}
+	if pipelineOpts.PDFA && len(pipelineOpts.Metadata.Properties) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "pdfa cannot be combined with custom metadata properties"})
 		return
 	}
 
-	// Ensure intermediate files are cleaned up
-	defer merger.Cleanup(pdfFiles)
+	// Context for the request is passed down
+	ctx := c.Request.Context()
 
-	// 2. Merge PDFs into the single output file
-	if err := merger.MergePDFs(pdfFiles, outputPath); err != nil {
-		slog.Error("merge failed", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("merge failed: %v", err)})
+	// 1. Convert all sources and merge them into a single document, held in
+	// memory unless it outgrows the spill threshold
+	out, report, err := pipeline.Generate(ctx, sources, pipelineOpts)
+	if errors.Is(err, pipeline.ErrInvalidOutput) {
+		slog.Warn("generated PDF rejected", "error", err)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":             err.Error(),
+			"validation_errors": report.ValidationErrors,
+			"conformance":       conformanceChecks(report.Conformance),
+		})
 		return
 	}
-
-	// 3. Read the merged PDF and save it using the configured storage backend
-	data, err := os.ReadFile(outputPath)
 	if err != nil {
-		slog.Error("failed to read merged PDF", "error", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		slog.Error("generation failed", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	// Ensure the merged document, and any file it spilled to, is released
+	defer out.Close()
 
-	fileURL, err := h.Storage.Save(ctx, "document.pdf", data)
+	// 2. Stream the merged PDF to the configured storage backend
+	fileURL, err := h.Storage.SaveStream(ctx, "document.pdf", out.Reader())
 	if err != nil {
 		slog.Error("failed to save PDF to storage", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("storage failed: %v", err)})
 		return
 	}
 
+	resp := GenerateResponse{
+		URL:        fileURL,
+		SizeBefore: report.SizeBefore,
+		SizeAfter:  report.SizeAfter,
+		// Problems are reported, the document was kept in warn mode
+		ValidationErrors: report.ValidationErrors,
+		Conformance:      conformanceChecks(report.Conformance),
+	}
+
+	// 3. Describe the stored PDF, when asked to
+	if opts.Inspect {
+		var password string
+		if enc := opts.Encryption; enc != nil {
+			password = cmp.Or(enc.OwnerPassword, enc.UserPassword)
+		}
+		in, err := merger.Inspect(out.Reader(), password)
+		if err != nil {
+			slog.Error("failed to inspect PDF", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("inspection failed: %v", err)})
+			return
+		}
+		resp.Inspection = NewInspection(in)
+	}
+
 	// 4. Return the URL where the PDF can be accessed
-	c.JSON(http.StatusOK, GenerateResponse{URL: fileURL})
+	c.JSON(http.StatusOK, resp)
 }
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/psilva1982/rapid_pdf/internal/merger"
+)
+
+// ImposeRequest defines the parameters of an imposition of an existing PDF.
+// The PDF is either uploaded in the multipart "file" field or referenced by
+// Source.
+type ImposeRequest struct {
+	// Source is the URL or storage key of a previously generated PDF.
+	Source string `json:"source" form:"source"`
+	Mode   string `json:"mode" form:"mode" binding:"required,oneof=2-up 4-up 9-up booklet"`
+	// PaperSize is the sheet size, such as "A4" or "Letter". Defaults to "A4".
+	PaperSize string `json:"paper_size" form:"paper_size"`
+	Landscape bool   `json:"landscape" form:"landscape"`
+	// Border frames every page on the sheet.
+	Border bool `json:"border" form:"border"`
+	// Margin is the space around every page on the sheet in points.
+	Margin float64 `json:"margin" form:"margin" binding:"min=0"`
+	// Validation checks the output against the specification: "off",
+	// "warn" (default) reports problems in the response and "fail" rejects
+	// the request.
+	Validation string `json:"validation" form:"validation" binding:"omitempty,oneof=off warn fail"`
+}
+
+// ImposeResponse defines the JSON response returned after an imposition.
+type ImposeResponse struct {
+	URL string `json:"url"`
+	// ValidationErrors lists where the imposed PDF breaks the PDF
+	// specification.
+	ValidationErrors []string `json:"validation_errors,omitempty"`
+}
+
+// ImposePDF handles laying out the pages of an existing PDF on sheets.
+//
+// @Summary      Impose a PDF
+// @Description  Lays the pages of an uploaded or previously generated PDF out 2, 4 or 9 to a sheet, or reorders them into a saddle-stitched booklet, and saves the result to storage (S3 or local). Bookmarks and links are dropped.
+// @Tags         pdf
+// @Accept       json,multipart/form-data
+// @Produce      json
+// @Param        request body ImposeRequest false "Imposition parameters (JSON requests)"
+// @Param        file formData file false "PDF to impose (multipart requests)"
+// @Param        source formData string false "URL or storage key of a previously generated PDF"
+// @Param        mode formData string true "Imposition mode" Enums(2-up, 4-up, 9-up, booklet)
+// @Param        paper_size formData string false "Sheet size, e.g. A4 or Letter (default A4)"
+// @Param        landscape formData bool false "Turn the sheets sideways"
+// @Param        border formData bool false "Frame every page on the sheet"
+// @Param        margin formData number false "Space around every page on the sheet in points"
+// @Param        validation formData string false "Output validation: off, warn (default) or fail"
+// @Success      200 {object} ImposeResponse "URL of the imposed PDF"
+// @Failure      400 {object} map[string]string "Bad Request"
+// @Failure      404 {object} map[string]string "Not Found"
+// @Failure      413 {object} map[string]string "Request Entity Too Large"
+// @Failure      422 {object} map[string]any "Output PDF failed validation"
+// @Failure      500 {object} map[string]string "Internal Server Error"
+// @Router       /impose [post]
+func (h *Handler) ImposePDF(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.Config.MaxUploadBytes())
+
+	var req ImposeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.bindError(c, err)
+		return
+	}
+
+	imp := merger.Imposition{
+		Mode:      req.Mode,
+		PaperSize: req.PaperSize,
+		Landscape: req.Landscape,
+		Border:    req.Border,
+		Margin:    req.Margin,
+	}
+	if err := imp.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid imposition: %v", err)})
+		return
+	}
+
+	workDir, err := os.MkdirTemp("", "rapid_pdf_impose_*")
+	if err != nil {
+		slog.Error("failed to create impose dir", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer os.RemoveAll(workDir)
+
+	input, ok := h.loadPDF(c, req.Source, workDir)
+	if !ok {
+		return
+	}
+
+	slog.Info("received impose request", "mode", req.Mode, "paper_size", req.PaperSize)
+
+	output := filepath.Join(workDir, "imposed.pdf")
+	if err := imposeFile(input, output, imp); err != nil {
+		slog.Warn("imposition failed", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("imposition failed: %v", err)})
+		return
+	}
+
+	problems, ok := validateFile(c, output, req.Validation)
+	if !ok {
+		return
+	}
+
+	f, err := os.Open(output)
+	if err != nil {
+		slog.Error("failed to read imposed PDF", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer f.Close()
+
+	fileURL, err := h.Storage.SaveStream(c.Request.Context(), "imposed.pdf", f)
+	if err != nil {
+		slog.Error("failed to save imposed PDF to storage", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("storage failed: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, ImposeResponse{URL: fileURL, ValidationErrors: problems})
+}
+
+// imposeFile imposes the PDF at input and writes the result to output.
+func imposeFile(input, output string, imp merger.Imposition) error {
+	in, err := os.Open(input)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := merger.Impose(in, out, imp); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
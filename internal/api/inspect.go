@@ -0,0 +1,190 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/psilva1982/rapid_pdf/internal/merger"
+)
+
+// InspectRequest identifies the PDF to inspect. The PDF is either uploaded in
+// the multipart "file" field or referenced by Source.
+type InspectRequest struct {
+	// Source is the URL or storage key of a stored PDF.
+	Source string `json:"source" form:"source"`
+	// Password opens encrypted PDFs.
+	Password string `json:"password" form:"password"`
+}
+
+// Inspection describes a PDF: its pages, metadata, fonts, security,
+// attachments, bookmarks and whether it conforms to the PDF specification.
+type Inspection struct {
+	Version   string     `json:"version"`
+	Pages     int        `json:"pages"`
+	PageSizes []PageSize `json:"page_sizes"`
+	Metadata  Metadata   `json:"metadata"`
+	// CreationDate and ModDate are raw PDF dates, e.g.
+	// "D:20240131120000+00'00'".
+	CreationDate string `json:"creation_date,omitempty"`
+	ModDate      string `json:"mod_date,omitempty"`
+	Tagged       bool   `json:"tagged"`
+	Encrypted    bool   `json:"encrypted"`
+	// Permissions lists what an encrypted PDF allows, e.g. "print" or
+	// "copy". It is null for PDFs that are not encrypted.
+	Permissions []string         `json:"permissions"`
+	Signed      bool             `json:"signed"`
+	Form        bool             `json:"form"`
+	Fonts       []Font           `json:"fonts"`
+	Attachments []AttachmentInfo `json:"attachments"`
+	Bookmarks   []Bookmark       `json:"bookmarks"`
+	// Valid is false when the PDF breaks the specification, as described by
+	// ValidationErrors.
+	Valid            bool     `json:"valid"`
+	ValidationErrors []string `json:"validation_errors,omitempty"`
+}
+
+// PageSize is a page size in points along with the pages that have it.
+type PageSize struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+	// Paper is the matching paper name, such as "A4", if any.
+	Paper string `json:"paper,omitempty"`
+	// Pages lists the pages of this size, e.g. "1-3,7".
+	Pages string `json:"pages"`
+}
+
+// Font describes a font used by a PDF.
+type Font struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Embedded bool   `json:"embedded"`
+	Subset   bool   `json:"subset"`
+}
+
+// AttachmentInfo describes a file embedded into a PDF.
+type AttachmentInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Bookmark is an entry of the outline of a PDF.
+type Bookmark struct {
+	Title    string     `json:"title"`
+	Page     int        `json:"page"`
+	Children []Bookmark `json:"children,omitempty"`
+}
+
+// NewInspection converts a merger inspection into its JSON representation.
+func NewInspection(in *merger.Inspection) *Inspection {
+	out := &Inspection{
+		Version:   in.Version,
+		Pages:     in.Pages,
+		PageSizes: make([]PageSize, 0, len(in.PageSizes)),
+		Metadata: Metadata{
+			Title:      in.Metadata.Title,
+			Author:     in.Metadata.Author,
+			Subject:    in.Metadata.Subject,
+			Keywords:   in.Metadata.Keywords,
+			Creator:    in.Metadata.Creator,
+			Producer:   in.Metadata.Producer,
+			Language:   in.Metadata.Language,
+			Properties: in.Metadata.Properties,
+		},
+		CreationDate:     in.CreationDate,
+		ModDate:          in.ModDate,
+		Tagged:           in.Tagged,
+		Encrypted:        in.Encrypted,
+		Permissions:      in.Permissions,
+		Signed:           in.Signed,
+		Form:             in.Form,
+		Fonts:            make([]Font, 0, len(in.Fonts)),
+		Attachments:      make([]AttachmentInfo, 0, len(in.Attachments)),
+		Bookmarks:        bookmarks(in.Bookmarks),
+		Valid:            in.Valid,
+		ValidationErrors: in.ValidationErrors,
+	}
+	for _, s := range in.PageSizes {
+		out.PageSizes = append(out.PageSizes, PageSize{Width: s.Width, Height: s.Height, Paper: s.Paper, Pages: s.Pages})
+	}
+	for _, f := range in.Fonts {
+		out.Fonts = append(out.Fonts, Font{Name: f.Name, Type: f.Type, Embedded: f.Embedded, Subset: f.Subset})
+	}
+	for _, a := range in.Attachments {
+		out.Attachments = append(out.Attachments, AttachmentInfo{Name: a.Name, Description: a.Description})
+	}
+	return out
+}
+
+// bookmarks converts a merger outline, never returning nil at the top level
+// so that an empty outline is sent as [].
+func bookmarks(in []merger.BookmarkInfo) []Bookmark {
+	out := make([]Bookmark, 0, len(in))
+	for _, b := range in {
+		bm := Bookmark{Title: b.Title, Page: b.Page}
+		if len(b.Children) > 0 {
+			bm.Children = bookmarks(b.Children)
+		}
+		out = append(out, bm)
+	}
+	return out
+}
+
+// InspectPDF handles describing an existing PDF.
+//
+// @Summary      Inspect a PDF
+// @Description  Reports the version, page count and sizes, metadata, fonts, encryption, attachments, bookmarks and validation errors of an uploaded or stored PDF, without modifying it.
+// @Tags         pdf
+// @Accept       json,multipart/form-data
+// @Produce      json
+// @Param        request body InspectRequest false "PDF to inspect (JSON requests)"
+// @Param        file formData file false "PDF to inspect (multipart requests)"
+// @Param        source formData string false "URL or storage key of a stored PDF"
+// @Param        password formData string false "Password of an encrypted PDF"
+// @Success      200 {object} Inspection "Description of the PDF"
+// @Failure      400 {object} map[string]string "Bad Request"
+// @Failure      404 {object} map[string]string "Not Found"
+// @Failure      413 {object} map[string]string "Request Entity Too Large"
+// @Failure      500 {object} map[string]string "Internal Server Error"
+// @Router       /inspect [post]
+func (h *Handler) InspectPDF(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.Config.MaxUploadBytes())
+
+	var req InspectRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.bindError(c, err)
+		return
+	}
+
+	workDir, err := os.MkdirTemp("", "rapid_pdf_inspect_*")
+	if err != nil {
+		slog.Error("failed to create inspect dir", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer os.RemoveAll(workDir)
+
+	input, ok := h.loadPDF(c, req.Source, workDir)
+	if !ok {
+		return
+	}
+
+	f, err := os.Open(input)
+	if err != nil {
+		slog.Error("failed to open PDF", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer f.Close()
+
+	in, err := merger.Inspect(f, req.Password)
+	if err != nil {
+		slog.Warn("inspection failed", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("inspection failed: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, NewInspection(in))
+}
@@ -0,0 +1,476 @@
+package api
+
+import (
+	"cmp"
+	"strings"
+	"time"
+
+	"github.com/psilva1982/rapid_pdf/internal/converter"
+	"github.com/psilva1982/rapid_pdf/internal/merger"
+	"github.com/psilva1982/rapid_pdf/internal/pipeline"
+)
+
+// GenerateOptions holds the per-request settings shared by every generate
+// endpoint. They are read from the JSON body or from multipart form fields
+// of the same name.
+type GenerateOptions struct {
+	// SinglePage prints every source on one continuous page as tall as the
+	// document instead of A4 pages.
+	SinglePage bool `json:"single_page" form:"single_page"`
+
+	// Locale sets the browser locale for date, number and currency
+	// formatting, e.g. "pt-BR".
+	Locale string `json:"locale" form:"locale"`
+	// TimezoneID sets the browser timezone, e.g. "America/Sao_Paulo".
+	TimezoneID string `json:"timezone_id" form:"timezone_id"`
+	// AcceptLanguage sets the Accept-Language header. Defaults to Locale.
+	AcceptLanguage string `json:"accept_language" form:"accept_language"`
+	// Geolocation is the position reported to pages using the Geolocation
+	// API. Multipart requests send it as a JSON object in a "geolocation"
+	// field.
+	Geolocation *Geolocation `json:"geolocation" form:"geolocation"`
+
+	// Cover prepends a cover page. Multipart requests send it as a JSON
+	// object in a "cover" field.
+	Cover *Cover `json:"cover" form:"cover"`
+
+	// TOC prepends a table of contents listing every source with its
+	// starting page.
+	TOC bool `json:"toc" form:"toc"`
+	// TOCTitle is the heading of the table of contents.
+	TOCTitle string `json:"toc_title" form:"toc_title"`
+
+	// PageLayout scales every page of the merged document to the same
+	// paper size. Multipart requests send it as a JSON object in a
+	// "page_layout" field.
+	PageLayout *PageLayout `json:"page_layout" form:"page_layout"`
+	// Duplex inserts blank pages so every source starts on a right-hand
+	// page when printed double-sided.
+	Duplex bool `json:"duplex" form:"duplex"`
+	// Separators inserts a page between consecutive sources. Multipart
+	// requests send it as a JSON object in a "separators" field.
+	Separators *Separators `json:"separators" form:"separators"`
+
+	// PageNumbers stamps continuous page numbers across the merged
+	// document. Multipart requests send it as a JSON object in a
+	// "page_numbers" field.
+	PageNumbers *PageNumbers `json:"page_numbers" form:"page_numbers"`
+
+	// Watermarks are text or image marks applied to the merged document.
+	// Multipart requests send each one as a JSON object in a "watermarks"
+	// field.
+	Watermarks []Watermark `json:"watermarks" form:"watermarks" binding:"dive"`
+
+	// Imposition lays several pages out on each sheet ("2-up", "4-up",
+	// "9-up") or turns the document into a booklet. Multipart requests send
+	// it as a JSON object in an "imposition" field.
+	Imposition *Imposition `json:"imposition" form:"imposition"`
+
+	// Print adjusts the generated PDF for printing on paper, e.g. in
+	// grayscale and without backgrounds. Multipart requests send it as a
+	// JSON object in a "print" field.
+	Print *Print `json:"print" form:"print"`
+
+	// Attachments embeds provenance files, such as the source URLs and the
+	// request itself, into the generated PDF. Multipart requests send it as
+	// a JSON object in an "attachments" field.
+	Attachments *Attachments `json:"attachments" form:"attachments"`
+
+	// Compression shrinks the output: "none" (default), "standard" or
+	// "aggressive".
+	Compression string `json:"compression" form:"compression" binding:"omitempty,oneof=none standard aggressive"`
+	// ImageDPI overrides the resolution images are downsampled to when
+	// compressing.
+	ImageDPI int `json:"image_dpi" form:"image_dpi" binding:"omitempty,min=36,max=1200"`
+
+	// Encryption protects the generated PDF with AES-256. Multipart
+	// requests send it as a JSON object in an "encryption" field.
+	Encryption *Encryption `json:"encryption" form:"encryption"`
+
+	// PDFA makes the generated PDF conform to PDF/A-2b for archival. It
+	// cannot be combined with encryption or custom metadata properties.
+	PDFA bool `json:"pdfa" form:"pdfa"`
+
+	// Metadata sets the document information of the generated PDF.
+	// Multipart requests send it as a JSON object in a "metadata" field.
+	Metadata Metadata `json:"metadata" form:"metadata"`
+
+	// Signature digitally signs the generated PDF with the server
+	// certificate. Multipart requests send it as a JSON object in a
+	// "signature" field.
+	Signature *Signature `json:"signature" form:"signature"`
+
+	// Inspect adds a description of the generated PDF, as returned by
+	// /inspect, to the response.
+	Inspect bool `json:"inspect" form:"inspect"`
+
+	// Validation checks the generated PDF against the specification:
+	// "off", "warn" (default) reports problems in the response and "fail"
+	// rejects the document.
+	Validation string `json:"validation" form:"validation" binding:"omitempty,oneof=off warn fail"`
+}
+
+// Geolocation defines a position in decimal degrees with an accuracy radius
+// in meters.
+type Geolocation struct {
+	Latitude  float64 `json:"latitude" binding:"min=-90,max=90"`
+	Longitude float64 `json:"longitude" binding:"min=-180,max=180"`
+	Accuracy  float64 `json:"accuracy" binding:"min=0"`
+}
+
+// Metadata defines the document information written to the generated PDF.
+// Author, Creator, Producer and Language default to the server configuration;
+// Language falls back to the browser locale.
+type Metadata struct {
+	Title    string   `json:"title"`
+	Author   string   `json:"author"`
+	Subject  string   `json:"subject"`
+	Keywords []string `json:"keywords"`
+	Creator  string   `json:"creator"`
+	Producer string   `json:"producer"`
+	// Language is a BCP 47 tag such as "pt-BR".
+	Language string `json:"language"`
+	// Properties are custom info dictionary entries.
+	Properties map[string]string `json:"properties"`
+}
+
+// Cover defines the cover page rendered from the built-in template or from a
+// custom one.
+type Cover struct {
+	Title    string `json:"title" binding:"required_without=Template"`
+	Subtitle string `json:"subtitle"`
+	Author   string `json:"author"`
+	// Date is printed as is. Defaults to today's date.
+	Date string `json:"date"`
+	// Logo is a base64-encoded PNG, JPEG, GIF, WebP or SVG image.
+	Logo []byte `json:"logo"`
+	// Template is a custom HTML template that may use {{.Title}},
+	// {{.Subtitle}}, {{.Author}}, {{.Date}} and {{.Logo}}, a data URL.
+	Template string `json:"template"`
+}
+
+// toCover converts the request into a converter cover.
+func (c Cover) toCover() converter.Cover {
+	return converter.Cover{
+		Title:    c.Title,
+		Subtitle: c.Subtitle,
+		Author:   c.Author,
+		Date:     c.Date,
+		Logo:     c.Logo,
+		Template: c.Template,
+	}
+}
+
+// PageLayout defines the paper every page of the merged document is scaled
+// to, so pages of mixed sizes and orientations print alike.
+type PageLayout struct {
+	// PaperSize is a paper name such as "A4" or "Letter". Defaults to "A4".
+	PaperSize string `json:"paper_size"`
+	Landscape bool   `json:"landscape"`
+	// Scale is "fit" (default), which keeps the whole page visible, or
+	// "fill", which covers the paper and crops the overflow.
+	Scale string `json:"scale" binding:"omitempty,oneof=fit fill"`
+	// AutoRotate turns pages whose orientation differs from the paper's.
+	AutoRotate bool `json:"auto_rotate"`
+	// Margin is the blank border on every edge of the paper in points.
+	Margin float64 `json:"margin" binding:"min=0"`
+}
+
+// toPageLayout converts the request into a merger page layout.
+func (l PageLayout) toPageLayout() merger.PageLayout {
+	return merger.PageLayout{
+		PaperSize:  l.PaperSize,
+		Landscape:  l.Landscape,
+		Scale:      l.Scale,
+		AutoRotate: l.AutoRotate,
+		Margin:     l.Margin,
+	}
+}
+
+// Separators defines the pages inserted between consecutive sources. An empty
+// object inserts blank pages.
+type Separators struct {
+	// Titled announces the title of the next source on the separator page.
+	Titled bool `json:"titled"`
+	// Template is a custom HTML template for titled separators that may use
+	// {{.Title}}, {{.Number}} and {{.Total}}. It implies Titled.
+	Template string `json:"template"`
+}
+
+// PageNumbers defines the labels, such as "Page 3 of 10", stamped on every
+// page of the merged document.
+type PageNumbers struct {
+	// Format may use {page} and {total}. Defaults to "Page {page} of {total}".
+	Format   string `json:"format"`
+	Position string `json:"position" binding:"omitempty,oneof=tl tc tr l c r bl bc br"`
+	Font     string `json:"font"`
+	FontSize int    `json:"font_size" binding:"min=0"`
+	Color    string `json:"color" binding:"omitempty,hexcolor"`
+	// Margin is the distance from the page edge in points.
+	Margin float64 `json:"margin" binding:"min=0"`
+	// Start is the number of the first numbered page. Defaults to 1.
+	Start int `json:"start" binding:"min=0"`
+	// Skip leaves the first pages unnumbered.
+	Skip int `json:"skip" binding:"min=0"`
+	// SkipFrontMatter leaves the cover and table of contents unnumbered.
+	SkipFrontMatter bool `json:"skip_front_matter"`
+}
+
+// toPageNumbering converts the request into a merger page numbering.
+func (p PageNumbers) toPageNumbering() merger.PageNumbering {
+	return merger.PageNumbering{
+		Format:   p.Format,
+		Position: p.Position,
+		FontName: p.Font,
+		FontSize: p.FontSize,
+		Color:    p.Color,
+		Margin:   p.Margin,
+		Start:    p.Start,
+		Skip:     p.Skip,
+	}
+}
+
+// Watermark defines a text or image mark such as "DRAFT" or a company logo.
+// Exactly one of Text and Image must be set.
+type Watermark struct {
+	// Text may use %p and %P for the page number and page count.
+	Text string `json:"text"`
+	// Image is a base64-encoded PNG or JPEG.
+	Image []byte `json:"image"`
+	// Stamp draws the mark over the page content instead of behind it.
+	Stamp bool `json:"stamp"`
+
+	Font     string  `json:"font"`
+	FontSize int     `json:"font_size" binding:"min=0"`
+	Color    string  `json:"color" binding:"omitempty,hexcolor"`
+	Opacity  float64 `json:"opacity" binding:"min=0,max=1"`
+	// Rotation in degrees; when omitted the mark follows the page diagonal.
+	Rotation *float64 `json:"rotation" binding:"omitempty,min=-180,max=180"`
+	Position string   `json:"position" binding:"omitempty,oneof=tl tc tr l c r bl bc br"`
+	OffsetX  float64  `json:"offset_x"`
+	OffsetY  float64  `json:"offset_y"`
+	// Scale is the mark width relative to the page width.
+	Scale float64 `json:"scale" binding:"min=0,max=1"`
+	// Pages selects the pages to mark, e.g. "1-3,odd". Defaults to all.
+	Pages string `json:"pages"`
+}
+
+// toWatermark converts the request into a merger watermark.
+func (w Watermark) toWatermark() merger.Watermark {
+	var pages []string
+	if w.Pages != "" {
+		pages = strings.Split(w.Pages, ",")
+	}
+
+	return merger.Watermark{
+		Text:     w.Text,
+		Image:    w.Image,
+		OnTop:    w.Stamp,
+		FontName: w.Font,
+		FontSize: w.FontSize,
+		Color:    w.Color,
+		Opacity:  w.Opacity,
+		Rotation: w.Rotation,
+		Position: w.Position,
+		OffsetX:  w.OffsetX,
+		OffsetY:  w.OffsetY,
+		Scale:    w.Scale,
+		Pages:    pages,
+	}
+}
+
+// Imposition defines how pages are laid out on printed sheets.
+type Imposition struct {
+	Mode string `json:"mode" binding:"required,oneof=2-up 4-up 9-up booklet"`
+	// PaperSize is the sheet size, such as "A4" or "Letter". Defaults to "A4".
+	PaperSize string `json:"paper_size"`
+	Landscape bool   `json:"landscape"`
+	// Border frames every page on the sheet.
+	Border bool `json:"border"`
+	// Margin is the space around every page on the sheet in points.
+	Margin float64 `json:"margin" binding:"min=0"`
+}
+
+// toImposition converts the request into a merger imposition.
+func (i Imposition) toImposition() merger.Imposition {
+	return merger.Imposition{
+		Mode:      i.Mode,
+		PaperSize: i.PaperSize,
+		Landscape: i.Landscape,
+		Border:    i.Border,
+		Margin:    i.Margin,
+	}
+}
+
+// Print defines how the generated PDF is adjusted for paper. The settings
+// apply to every source alike.
+type Print struct {
+	// Preset is "toner_saver", which turns on every setting below, or
+	// "grayscale".
+	Preset string `json:"preset" binding:"omitempty,oneof=toner_saver grayscale"`
+	// OmitBackground prints web pages without background colors and
+	// images.
+	OmitBackground bool `json:"omit_background"`
+	// Grayscale renders every page in shades of gray.
+	Grayscale bool `json:"grayscale"`
+	// StripLinks prints links like plain text and removes them from the
+	// PDF.
+	StripLinks bool `json:"strip_links"`
+}
+
+// toPrint converts the request into pipeline print settings.
+func (p Print) toPrint() pipeline.Print {
+	return pipeline.Print{
+		Preset:         p.Preset,
+		OmitBackground: p.OmitBackground,
+		Grayscale:      p.Grayscale,
+		StripLinks:     p.StripLinks,
+	}
+}
+
+// Encryption defines the passwords and the permissions granted to readers who
+// open the document with the user password. Passwords are never logged or
+// returned.
+type Encryption struct {
+	UserPassword  string `json:"user_password"`
+	OwnerPassword string `json:"owner_password"`
+	AllowPrint    bool   `json:"allow_print"`
+	AllowCopy     bool   `json:"allow_copy"`
+	AllowModify   bool   `json:"allow_modify"`
+	AllowAnnotate bool   `json:"allow_annotate"`
+}
+
+// Signature defines the PAdES signature applied with the server certificate.
+// An empty object signs the document without a visible appearance.
+type Signature struct {
+	// Visible draws a box with the signer, date, reason and location.
+	Visible bool `json:"visible"`
+	// Page holds the visible signature. Defaults to the last page.
+	Page int `json:"page" binding:"min=0"`
+	// Rect is the box of a visible signature as [x1, y1, x2, y2] in points
+	// from the bottom left corner. Defaults to the bottom right corner.
+	Rect        []float64 `json:"rect" binding:"omitempty,len=4"`
+	Reason      string    `json:"reason"`
+	Location    string    `json:"location"`
+	ContactInfo string    `json:"contact_info"`
+}
+
+// toSignature converts the request into a merger signature.
+func (s Signature) toSignature() merger.Signature {
+	sig := merger.Signature{
+		Reason:      s.Reason,
+		Location:    s.Location,
+		ContactInfo: s.ContactInfo,
+		Visible:     s.Visible,
+		Page:        s.Page,
+	}
+	copy(sig.Rect[:], s.Rect)
+	return sig
+}
+
+// pipelineOptions combines the server configuration with the per-request
+// settings.
+func (h *Handler) pipelineOptions(o GenerateOptions) pipeline.Options {
+	opts := pipeline.Options{
+		Render: converter.Options{
+			Timeout:        time.Duration(h.Config.TimeoutSeconds) * time.Second,
+			WaitDelay:      time.Duration(h.Config.PageLoadWaitSeconds) * time.Second,
+			SinglePage:     o.SinglePage,
+			Locale:         o.Locale,
+			TimezoneID:     o.TimezoneID,
+			AcceptLanguage: o.AcceptLanguage,
+			SpillThreshold: h.Config.SpillThreshold(),
+			// Downloaded PDFs are held to the same limit as uploaded ones
+			MaxDownloadBytes: h.Config.MaxUploadBytes(),
+		},
+		TOC:         o.TOC,
+		TOCTitle:    o.TOCTitle,
+		Duplex:      o.Duplex,
+		Compression: o.Compression,
+		ImageDPI:    o.ImageDPI,
+		Signer:      h.Signer,
+		PDFA:        o.PDFA,
+		Validation:  o.Validation,
+		Metadata: merger.Metadata{
+			Title:      o.Metadata.Title,
+			Author:     cmp.Or(o.Metadata.Author, h.Config.PDFAuthor),
+			Subject:    o.Metadata.Subject,
+			Keywords:   o.Metadata.Keywords,
+			Creator:    cmp.Or(o.Metadata.Creator, h.Config.PDFCreator),
+			Producer:   cmp.Or(o.Metadata.Producer, h.Config.PDFProducer),
+			Language:   cmp.Or(o.Metadata.Language, o.Locale, h.Config.PDFLanguage),
+			Properties: o.Metadata.Properties,
+		},
+	}
+
+	if c := o.Cover; c != nil {
+		cover := c.toCover()
+		opts.Cover = &cover
+	}
+
+	if l := o.PageLayout; l != nil {
+		layout := l.toPageLayout()
+		opts.PageLayout = &layout
+	}
+
+	if s := o.Separators; s != nil {
+		opts.Separators = &pipeline.Separators{Titled: s.Titled, Template: s.Template}
+	}
+
+	if pn := o.PageNumbers; pn != nil {
+		numbering := pn.toPageNumbering()
+		opts.PageNumbers = &numbering
+		opts.SkipFrontMatter = pn.SkipFrontMatter
+	}
+
+	for _, w := range o.Watermarks {
+		opts.Watermarks = append(opts.Watermarks, w.toWatermark())
+	}
+
+	if imp := o.Imposition; imp != nil {
+		imposition := imp.toImposition()
+		opts.Imposition = &imposition
+	}
+
+	if p := o.Print; p != nil {
+		printing := p.toPrint()
+		opts.Print = &printing
+	}
+
+	if enc := o.Encryption; enc != nil {
+		opts.Encryption = &merger.Encryption{
+			UserPassword:  enc.UserPassword,
+			OwnerPassword: enc.OwnerPassword,
+			Permissions: merger.Permissions{
+				Print:    enc.AllowPrint,
+				Copy:     enc.AllowCopy,
+				Modify:   enc.AllowModify,
+				Annotate: enc.AllowAnnotate,
+			},
+		}
+	}
+
+	if o.Signature != nil {
+		sig := o.Signature.toSignature()
+		opts.Signature = &sig
+	}
+
+	if geo := o.Geolocation; geo != nil {
+		opts.Render.Geolocation = &converter.Geolocation{
+			Latitude:  geo.Latitude,
+			Longitude: geo.Longitude,
+			Accuracy:  geo.Accuracy,
+		}
+	}
+
+	return opts
+}
+
+// attachments returns the requested attachments, or none when only files
+// were uploaded.
+func (o GenerateOptions) attachments() Attachments {
+	if o.Attachments == nil {
+		return Attachments{}
+	}
+	return *o.Attachments
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/psilva1982/rapid_pdf/internal/converter"
+	"github.com/psilva1982/rapid_pdf/internal/markdown"
+)
+
+// Source types accepted in GenerateRequest.Sources.
+const (
+	SourceTypeURL      = "url"
+	SourceTypeMarkdown = "markdown"
+)
+
+// SourceRequest describes a single typed source of a generate request.
+type SourceRequest struct {
+	// Type selects how the source is rendered: "url" or "markdown".
+	Type string `json:"type" binding:"required,oneof=url markdown"`
+	// URL is the page to convert when Type is "url".
+	URL string `json:"url,omitempty"`
+	// Markdown is the GitHub Flavored Markdown document when Type is "markdown".
+	Markdown string `json:"markdown,omitempty"`
+	// Theme is the built-in stylesheet for Markdown: github, minimal or serif.
+	Theme string `json:"theme,omitempty"`
+	// Stylesheet is custom CSS applied on top of the Markdown theme.
+	Stylesheet string `json:"stylesheet,omitempty"`
+	// Label is the bookmark title of the source in the merged PDF. Defaults
+	// to the page <title>.
+	Label string `json:"label,omitempty"`
+}
+
+// toSource validates the request and turns it into a conversion source.
+// Markdown is rendered to HTML here so the converter only ever prints HTML.
+func (sr SourceRequest) toSource() (converter.Source, error) {
+	switch sr.Type {
+	case SourceTypeURL:
+		if sr.URL == "" {
+			return converter.Source{}, fmt.Errorf("empty URL provided")
+		}
+		return converter.Source{URL: sr.URL, Label: sr.Label}, nil
+
+	case SourceTypeMarkdown:
+		if sr.Markdown == "" {
+			return converter.Source{}, fmt.Errorf("empty markdown provided")
+		}
+		if sr.Theme != "" && !markdown.IsTheme(sr.Theme) {
+			return converter.Source{}, fmt.Errorf("unknown theme %q, available: %v", sr.Theme, markdown.Themes())
+		}
+
+		html, err := markdown.Render([]byte(sr.Markdown), markdown.Options{
+			Theme:      sr.Theme,
+			Stylesheet: sr.Stylesheet,
+		})
+		if err != nil {
+			return converter.Source{}, err
+		}
+		return converter.Source{HTML: html, Label: sr.Label}, nil
+
+	default:
+		return converter.Source{}, fmt.Errorf("unsupported source type %q", sr.Type)
+	}
+}
@@ -0,0 +1,328 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/psilva1982/rapid_pdf/internal/merger"
+	"github.com/psilva1982/rapid_pdf/internal/pipeline"
+	"github.com/psilva1982/rapid_pdf/internal/storage"
+)
+
+// sourceFileField is the multipart form field that carries the PDF to split
+// or extract pages from.
+const sourceFileField = "file"
+
+// SplitRequest defines the parameters of a split. The PDF is either uploaded
+// in the multipart "file" field or referenced by Source. Exactly one of
+// Ranges, Every and Bookmarks must be set.
+type SplitRequest struct {
+	// Source is the URL or storage key of a previously generated PDF.
+	Source string `json:"source" form:"source"`
+	// Ranges become one document each, e.g. ["1-3", "4,6-8"].
+	Ranges []string `json:"ranges" form:"ranges"`
+	// Every splits the PDF into documents of this many pages.
+	Every int `json:"every" form:"every" binding:"min=0"`
+	// Bookmarks starts a new document at every top-level bookmark.
+	Bookmarks bool `json:"bookmarks" form:"bookmarks"`
+	// Validation checks the output against the specification: "off",
+	// "warn" (default) reports problems in the response and "fail" rejects
+	// the request.
+	Validation string `json:"validation" form:"validation" binding:"omitempty,oneof=off warn fail"`
+}
+
+// ExtractRequest defines the parameters of a page extraction. The PDF is
+// either uploaded in the multipart "file" field or referenced by Source.
+type ExtractRequest struct {
+	// Source is the URL or storage key of a previously generated PDF.
+	Source string `json:"source" form:"source"`
+	// Pages selects the pages to keep, e.g. ["1-3", "7", "odd"].
+	Pages []string `json:"pages" form:"pages" binding:"required,min=1"`
+	// Validation checks the output against the specification: "off",
+	// "warn" (default) reports problems in the response and "fail" rejects
+	// the request.
+	Validation string `json:"validation" form:"validation" binding:"omitempty,oneof=off warn fail"`
+}
+
+// SplitFile describes one document produced by a split or an extraction.
+type SplitFile struct {
+	URL string `json:"url"`
+	// Pages lists the pages of the original PDF the document holds.
+	Pages string `json:"pages"`
+	// Title is the bookmark the document was split at, if any.
+	Title string `json:"title,omitempty"`
+	// ValidationErrors lists where the document breaks the PDF
+	// specification.
+	ValidationErrors []string `json:"validation_errors,omitempty"`
+}
+
+// SplitResponse defines the JSON response returned after a split or an
+// extraction.
+type SplitResponse struct {
+	Files []SplitFile `json:"files"`
+}
+
+// SplitPDF handles splitting an existing PDF into several documents.
+//
+// @Summary      Split a PDF
+// @Description  Splits an uploaded or previously generated PDF by page ranges, every N pages or by top-level bookmarks, and saves every part to storage (S3 or local).
+// @Tags         pdf
+// @Accept       json,multipart/form-data
+// @Produce      json
+// @Param        request body SplitRequest false "Split parameters (JSON requests)"
+// @Param        file formData file false "PDF to split (multipart requests)"
+// @Param        source formData string false "URL or storage key of a previously generated PDF"
+// @Param        ranges formData []string false "Page ranges, one document each, e.g. 1-3"
+// @Param        every formData int false "Split into documents of this many pages"
+// @Param        bookmarks formData bool false "Split at every top-level bookmark"
+// @Param        validation formData string false "Output validation: off, warn (default) or fail"
+// @Success      200 {object} SplitResponse "URLs of the parts, in order"
+// @Failure      400 {object} map[string]string "Bad Request"
+// @Failure      404 {object} map[string]string "Not Found"
+// @Failure      413 {object} map[string]string "Request Entity Too Large"
+// @Failure      422 {object} map[string]any "Output PDF failed validation"
+// @Failure      500 {object} map[string]string "Internal Server Error"
+// @Router       /split [post]
+func (h *Handler) SplitPDF(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.Config.MaxUploadBytes())
+
+	var req SplitRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.bindError(c, err)
+		return
+	}
+
+	split := merger.Split{
+		Ranges:    req.Ranges,
+		Every:     req.Every,
+		Bookmarks: req.Bookmarks,
+	}
+	if err := split.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	workDir, err := os.MkdirTemp("", "rapid_pdf_split_*")
+	if err != nil {
+		slog.Error("failed to create split dir", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer os.RemoveAll(workDir)
+
+	input, ok := h.loadPDF(c, req.Source, workDir)
+	if !ok {
+		return
+	}
+
+	slog.Info("received split request", "ranges", len(req.Ranges), "every", req.Every, "bookmarks", req.Bookmarks)
+
+	parts, err := merger.SplitPDF(input, filepath.Join(workDir, "parts"), split)
+	if err != nil {
+		slog.Warn("split failed", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("split failed: %v", err)})
+		return
+	}
+
+	h.saveParts(c, parts, req.Validation)
+}
+
+// ExtractPages handles extracting a selection of pages from an existing PDF
+// into a new document.
+//
+// @Summary      Extract pages from a PDF
+// @Description  Copies the selected pages of an uploaded or previously generated PDF into a new document and saves it to storage (S3 or local).
+// @Tags         pdf
+// @Accept       json,multipart/form-data
+// @Produce      json
+// @Param        request body ExtractRequest false "Extraction parameters (JSON requests)"
+// @Param        file formData file false "PDF to extract pages from (multipart requests)"
+// @Param        source formData string false "URL or storage key of a previously generated PDF"
+// @Param        pages formData []string true "Pages to keep, e.g. 1-3, 7 or odd"
+// @Param        validation formData string false "Output validation: off, warn (default) or fail"
+// @Success      200 {object} SplitResponse "URL of the extracted document"
+// @Failure      400 {object} map[string]string "Bad Request"
+// @Failure      404 {object} map[string]string "Not Found"
+// @Failure      413 {object} map[string]string "Request Entity Too Large"
+// @Failure      422 {object} map[string]any "Output PDF failed validation"
+// @Failure      500 {object} map[string]string "Internal Server Error"
+// @Router       /extract [post]
+func (h *Handler) ExtractPages(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.Config.MaxUploadBytes())
+
+	var req ExtractRequest
+	if err := c.ShouldBind(&req); err != nil {
+		h.bindError(c, err)
+		return
+	}
+
+	workDir, err := os.MkdirTemp("", "rapid_pdf_extract_*")
+	if err != nil {
+		slog.Error("failed to create extract dir", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer os.RemoveAll(workDir)
+
+	input, ok := h.loadPDF(c, req.Source, workDir)
+	if !ok {
+		return
+	}
+
+	slog.Info("received extract request", "pages", req.Pages)
+
+	output := filepath.Join(workDir, "extracted.pdf")
+	if err := merger.ExtractPages(input, output, req.Pages); err != nil {
+		slog.Warn("extraction failed", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("extraction failed: %v", err)})
+		return
+	}
+
+	h.saveParts(c, []merger.SplitPart{{Path: output, Pages: strings.Join(req.Pages, ",")}}, req.Validation)
+}
+
+// bindError writes the response for a request that failed to bind, telling
+// oversized uploads apart from invalid parameters.
+func (h *Handler) bindError(c *gin.Context, err error) {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("upload too large, max is %d MB", h.Config.MaxUploadSizeMB)})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// loadPDF stores the PDF to operate on in dir, taking it from the multipart
+// "file" upload or, failing that, from storage by source. It writes the
+// error response and returns false when there is no usable PDF.
+func (h *Handler) loadPDF(c *gin.Context, source, dir string) (string, bool) {
+	path := filepath.Join(dir, "input.pdf")
+
+	fh, err := c.FormFile(sourceFileField)
+	switch {
+	case err == nil:
+		if source != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("provide either a %q upload or a source, not both", sourceFileField)})
+			return "", false
+		}
+		head, err := saveUpload(fh, path)
+		if err != nil {
+			slog.Error("failed to store upload", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return "", false
+		}
+		if kind := detectKind(fh.Filename, head); kind != "pdf" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("uploaded file is not a PDF (%s)", kind)})
+			return "", false
+		}
+
+	case source != "":
+		body, err := h.Storage.Load(c.Request.Context(), source)
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return "", false
+		}
+		if err != nil {
+			slog.Warn("failed to load source from storage", "source", source, "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return "", false
+		}
+		// Stored files are held to the upload limit too. One byte over the
+		// limit tells a file of exactly the limit from a larger one.
+		limit := h.Config.MaxUploadBytes()
+		head, size, err := saveFile(io.LimitReader(body, limit+1), path)
+		body.Close()
+		if err != nil {
+			slog.Error("failed to store source", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return "", false
+		}
+		if size > limit {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("source too large, max is %d MB", h.Config.MaxUploadSizeMB)})
+			return "", false
+		}
+		if kind := detectKind(source, head); kind != "pdf" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("source is not a PDF (%s)", kind)})
+			return "", false
+		}
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("a %q upload or a source is required", sourceFileField)})
+		return "", false
+	}
+
+	return path, true
+}
+
+// saveParts validates every part according to validation, then saves them
+// using the configured storage backend and writes their URLs as the JSON
+// response. Nothing is saved when a part is rejected.
+func (h *Handler) saveParts(c *gin.Context, parts []merger.SplitPart, validation string) {
+	problems := make([][]string, len(parts))
+	for i, part := range parts {
+		var ok bool
+		if problems[i], ok = validateFile(c, part.Path, validation); !ok {
+			return
+		}
+	}
+
+	files := make([]SplitFile, 0, len(parts))
+	for i, part := range parts {
+		f, err := os.Open(part.Path)
+		if err != nil {
+			slog.Error("failed to read part", "path", part.Path, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		fileURL, err := h.Storage.SaveStream(c.Request.Context(), filepath.Base(part.Path), f)
+		f.Close()
+		if err != nil {
+			slog.Error("failed to save part to storage", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("storage failed: %v", err)})
+			return
+		}
+
+		files = append(files, SplitFile{URL: fileURL, Pages: part.Pages, Title: part.Title, ValidationErrors: problems[i]})
+	}
+
+	c.JSON(http.StatusOK, SplitResponse{Files: files})
+}
+
+// validateFile checks the PDF at path according to validation, as
+// pipeline.Validate does for generated documents, and returns the problems
+// found. It writes the error response and returns false when the PDF is
+// rejected.
+func validateFile(c *gin.Context, path, validation string) ([]string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		slog.Error("failed to read output", "path", path, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return nil, false
+	}
+	defer f.Close()
+
+	var report pipeline.Report
+	err = pipeline.Validate(f, validation, "", false, &report)
+	if errors.Is(err, pipeline.ErrInvalidOutput) {
+		slog.Warn("output PDF rejected", "file", filepath.Base(path), "error", err)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":             err.Error(),
+			"validation_errors": report.ValidationErrors,
+		})
+		return nil, false
+	}
+	if err != nil {
+		slog.Error("output validation failed", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return report.ValidationErrors, true
+}
@@ -0,0 +1,277 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/psilva1982/rapid_pdf/internal/converter"
+	"github.com/psilva1982/rapid_pdf/internal/merger"
+)
+
+const (
+	// uploadField is the multipart form field that carries the source files.
+	uploadField = "files"
+	// labelsField optionally carries one bookmark title per uploaded file.
+	labelsField = "labels"
+)
+
+// GenerateFromUpload handles PDF generation from uploaded files.
+// Every file in the multipart "files" field becomes one source, in request
+// order: ZIP archives with an HTML page and its assets and plain HTML files
+// are rendered by Chrome, PNG/JPEG images become PDF pages, and PDFs are
+// passed through unchanged. Files in the multipart "attachment_files" field
+// are embedded into the generated PDF.
+//
+// @Summary      Generate PDF from uploaded files
+// @Description  Converts uploaded HTML (plain or zipped with assets), PNG/JPEG images and PDFs, merges them in order, and saves to storage (S3 or local).
+// @Tags         pdf
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        files formData file true "Source files, in merge order"
+// @Param        labels formData []string false "Bookmark title for each file, in the same order"
+// @Param        single_page formData bool false "Print each source on one continuous page"
+// @Param        locale formData string false "Browser locale, e.g. pt-BR"
+// @Param        timezone_id formData string false "Browser timezone, e.g. America/Sao_Paulo"
+// @Param        accept_language formData string false "Accept-Language header (defaults to locale)"
+// @Param        cover formData string false "Cover page settings as a JSON object"
+// @Param        toc formData bool false "Prepend a table of contents"
+// @Param        toc_title formData string false "Heading of the table of contents"
+// @Param        geolocation formData string false "Emulated geolocation (latitude, longitude, accuracy) as a JSON object"
+// @Param        page_layout formData string false "Uniform paper size settings as a JSON object"
+// @Param        duplex formData bool false "Start every source on a right-hand page"
+// @Param        separators formData string false "Separator pages settings as a JSON object"
+// @Param        page_numbers formData string false "Page numbering settings as a JSON object"
+// @Param        watermarks formData []string false "Watermarks, each as a JSON object"
+// @Param        imposition formData string false "N-up or booklet imposition settings as a JSON object"
+// @Param        print formData string false "Print settings (preset, omit_background, grayscale, strip_links) as a JSON object"
+// @Param        attachments formData string false "Provenance attachments settings as a JSON object"
+// @Param        attachment_files formData file false "Files to attach to the generated PDF"
+// @Param        compression formData string false "Output compression: none, standard or aggressive"
+// @Param        image_dpi formData int false "Resolution images are downsampled to when compressing"
+// @Param        encryption formData string false "AES-256 encryption settings (user_password, owner_password, allow_*) as a JSON object"
+// @Param        pdfa formData bool false "Make the PDF conform to PDF/A-2b (not with encryption or custom properties)"
+// @Param        metadata formData string false "Document information (title, author, subject, keywords, creator, producer, language, properties) as a JSON object"
+// @Param        signature formData string false "Digital signature settings as a JSON object"
+// @Param        inspect formData bool false "Describe the generated PDF in the response"
+// @Param        validation formData string false "Output validation: off, warn (default) or fail"
+// @Success      200 {object} GenerateResponse "URL of the generated PDF"
+// @Failure      400 {object} map[string]string "Bad Request"
+// @Failure      413 {object} map[string]string "Request Entity Too Large"
+// @Failure      422 {object} map[string]any "Generated PDF failed validation"
+// @Failure      500 {object} map[string]string "Internal Server Error"
+// @Router       /generate/upload [post]
+func (h *Handler) GenerateFromUpload(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.Config.MaxUploadBytes())
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("upload too large, max is %d MB", h.Config.MaxUploadSizeMB)})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var opts GenerateOptions
+	if err := c.ShouldBind(&opts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	files := form.File[uploadField]
+	if len(files) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("no files provided in %q field", uploadField)})
+		return
+	}
+
+	if len(files) > h.Config.MaxURLs {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("too many files provided, max is %d", h.Config.MaxURLs)})
+		return
+	}
+
+	labels := form.Value[labelsField]
+	if len(labels) > len(files) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("got %d labels for %d files", len(labels), len(files))})
+		return
+	}
+
+	slog.Info("received upload generate request", "file_count", len(files))
+
+	// Every upload is unpacked into its own folder under a single work dir.
+	workDir, err := os.MkdirTemp("", "rapid_pdf_upload_*")
+	if err != nil {
+		slog.Error("failed to create upload dir", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+	defer os.RemoveAll(workDir)
+
+	sources := make([]converter.Source, 0, len(files))
+	for i, fh := range files {
+		partDir := filepath.Join(workDir, fmt.Sprintf("part_%03d", i+1))
+
+		src, err := prepareUpload(fh, partDir)
+		if err != nil {
+			slog.Warn("rejected uploaded file", "index", i+1, "filename", fh.Filename, "error", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid file #%d (%s): %v", i+1, fh.Filename, err)})
+			return
+		}
+
+		src.Name = fh.Filename
+		if i < len(labels) {
+			src.Label = labels[i]
+		}
+		sources = append(sources, src)
+	}
+
+	uploads, err := readAttachments(form.File[attachmentFilesField])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	request := uploadRequest{Labels: labels, GenerateOptions: opts}
+	for _, fh := range files {
+		request.Files = append(request.Files, fh.Filename)
+	}
+	for _, a := range uploads {
+		request.AttachmentFiles = append(request.AttachmentFiles, a.Name)
+	}
+
+	h.generate(c, sources, opts, request, uploads)
+}
+
+// uploadRequest describes an upload generate request, naming the uploaded
+// files instead of carrying their content, for the attached request.
+type uploadRequest struct {
+	Files           []string `json:"files"`
+	Labels          []string `json:"labels,omitempty"`
+	AttachmentFiles []string `json:"attachment_files,omitempty"`
+	GenerateOptions
+}
+
+// prepareUpload stores a single uploaded file in dir and turns it into a
+// conversion source according to its content type. The upload keeps its
+// name inside a directory of its own, so it can never collide with the
+// files derived from it.
+func prepareUpload(fh *multipart.FileHeader, dir string) (converter.Source, error) {
+	inputDir := filepath.Join(dir, "input")
+	if err := os.MkdirAll(inputDir, 0755); err != nil {
+		return converter.Source{}, fmt.Errorf("failed to create dir: %w", err)
+	}
+
+	name := filepath.Base(fh.Filename)
+	if name == "." || name == string(filepath.Separator) {
+		name = "upload"
+	}
+	path := filepath.Join(inputDir, name)
+
+	head, err := saveUpload(fh, path)
+	if err != nil {
+		return converter.Source{}, err
+	}
+
+	switch kind := detectKind(name, head); kind {
+	case "pdf":
+		return converter.Source{PDFPath: path}, nil
+
+	case "image":
+		output := filepath.Join(dir, "image.pdf")
+		if err := merger.ImagesToPDF([]string{path}, output); err != nil {
+			return converter.Source{}, err
+		}
+		return converter.Source{PDFPath: output}, nil
+
+	case "zip":
+		siteDir := filepath.Join(dir, "site")
+		if err := extractZip(path, siteDir); err != nil {
+			return converter.Source{}, err
+		}
+		entry, err := findEntry(siteDir)
+		if err != nil {
+			return converter.Source{}, err
+		}
+		return converter.Source{Dir: siteDir, Entry: entry}, nil
+
+	case "html":
+		return converter.Source{Dir: inputDir, Entry: name}, nil
+
+	default:
+		return converter.Source{}, fmt.Errorf("unsupported file type %s", kind)
+	}
+}
+
+// saveUpload copies the uploaded file to path and returns its first bytes for
+// content sniffing.
+func saveUpload(fh *multipart.FileHeader, path string) ([]byte, error) {
+	in, err := fh.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open upload: %w", err)
+	}
+	defer in.Close()
+
+	head, _, err := saveFile(in, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store upload: %w", err)
+	}
+	return head, nil
+}
+
+// saveFile copies r to path and returns the first bytes of the content for
+// sniffing, along with its size.
+func saveFile(r io.Reader, path string) ([]byte, int64, error) {
+	out, err := os.Create(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer out.Close()
+
+	n, err := io.Copy(out, r)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	head := make([]byte, 512)
+	m, err := out.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, 0, err
+	}
+
+	return head[:m], n, nil
+}
+
+// detectKind classifies an upload as "pdf", "image", "zip" or "html" by
+// sniffing its content, falling back to the file extension for HTML that
+// sniffs as plain text. Anything else is returned as its MIME type.
+func detectKind(name string, head []byte) string {
+	contentType := http.DetectContentType(head)
+	mediaType, _, _ := strings.Cut(contentType, ";")
+
+	switch mediaType {
+	case "application/pdf":
+		return "pdf"
+	case "image/png", "image/jpeg":
+		return "image"
+	case "application/zip":
+		return "zip"
+	case "text/html":
+		return "html"
+	}
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".html", ".htm":
+		return "html"
+	}
+
+	return mediaType
+}
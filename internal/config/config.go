@@ -9,8 +9,11 @@ import (
 )
 
 const (
-	defaultMaxURLs        = 10
-	defaultTimeoutSeconds = 60
+	defaultMaxURLs         = 10
+	defaultTimeoutSeconds  = 60
+	defaultMaxUploadSizeMB = 50
+	defaultSpillThreshold  = 32
+	defaultPDFProducer     = "RapidPDF"
 )
 
 // Config holds the application configuration.
@@ -18,7 +21,24 @@ type Config struct {
 	MaxURLs             int
 	TimeoutSeconds      int
 	PageLoadWaitSeconds int
-	Port                string
+	MaxUploadSizeMB     int
+	// SpillThresholdMB is the size above which a document being generated
+	// moves from memory to a temporary file.
+	SpillThresholdMB int
+	Port             string
+
+	// Default document metadata, overridable per request.
+	PDFAuthor   string
+	PDFCreator  string
+	PDFProducer string
+	PDFLanguage string
+
+	// Digital signature configuration (optional — if empty, signing is
+	// unavailable). SignCertFile is a PKCS#12 bundle or a PEM certificate.
+	SignCertFile     string
+	SignKeyFile      string
+	SignCertPassword string
+	SignTSAURL       string
 
 	// S3 storage configuration (optional — if empty, files are saved locally).
 	S3Bucket    string
@@ -32,11 +52,28 @@ func (c *Config) IsS3Configured() bool {
 	return c.S3Bucket != "" && c.S3Region != "" && c.S3AccessKey != "" && c.S3SecretKey != ""
 }
 
+// IsSigningConfigured returns true when a signing certificate is set.
+func (c *Config) IsSigningConfigured() bool {
+	return c.SignCertFile != ""
+}
+
+// MaxUploadBytes returns the maximum accepted size of a multipart upload
+// request body in bytes.
+func (c *Config) MaxUploadBytes() int64 {
+	return int64(c.MaxUploadSizeMB) << 20
+}
+
+// SpillThreshold returns the size in bytes above which a document being
+// generated spills to disk.
+func (c *Config) SpillThreshold() int64 {
+	return int64(c.SpillThresholdMB) << 20
+}
+
 // Load reads the .env file and returns a Config with validated values.
 func Load() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		// .env is optional; log a warning but don't fail
-		fmt.Println("[warn] .env file not found, using defaults")
+		fmt.Fprintln(os.Stderr, "[warn] .env file not found, using defaults")
 	}
 
 	maxURLs := defaultMaxURLs
@@ -80,11 +117,50 @@ func Load() (*Config, error) {
 		pageLoadWaitSeconds = parsed
 	}
 
+	maxUploadSizeMB := defaultMaxUploadSizeMB
+	if v := os.Getenv("MAX_UPLOAD_SIZE_MB"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("MAX_UPLOAD_SIZE_MB must be a valid integer: %w", err)
+		}
+		if parsed < 1 {
+			return nil, fmt.Errorf("MAX_UPLOAD_SIZE_MB must be at least 1, got %d", parsed)
+		}
+		maxUploadSizeMB = parsed
+	}
+
+	spillThresholdMB := defaultSpillThreshold
+	if v := os.Getenv("SPILL_THRESHOLD_MB"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("SPILL_THRESHOLD_MB must be a valid integer: %w", err)
+		}
+		if parsed < 1 {
+			return nil, fmt.Errorf("SPILL_THRESHOLD_MB must be at least 1, got %d", parsed)
+		}
+		spillThresholdMB = parsed
+	}
+
+	pdfProducer := os.Getenv("PDF_PRODUCER")
+	if pdfProducer == "" {
+		pdfProducer = defaultPDFProducer
+	}
+
 	return &Config{
 		MaxURLs:             maxURLs,
 		TimeoutSeconds:      timeoutSeconds,
 		PageLoadWaitSeconds: pageLoadWaitSeconds,
+		MaxUploadSizeMB:     maxUploadSizeMB,
+		SpillThresholdMB:    spillThresholdMB,
 		Port:                port,
+		PDFAuthor:           os.Getenv("PDF_AUTHOR"),
+		PDFCreator:          os.Getenv("PDF_CREATOR"),
+		PDFProducer:         pdfProducer,
+		PDFLanguage:         os.Getenv("PDF_LANGUAGE"),
+		SignCertFile:        os.Getenv("SIGN_CERT_FILE"),
+		SignKeyFile:         os.Getenv("SIGN_KEY_FILE"),
+		SignCertPassword:    os.Getenv("SIGN_CERT_PASSWORD"),
+		SignTSAURL:          os.Getenv("SIGN_TSA_URL"),
 		S3Bucket:            os.Getenv("AWS_S3_BUCKET"),
 		S3Region:            os.Getenv("AWS_S3_REGION"),
 		S3AccessKey:         os.Getenv("AWS_S3_ACCESS_KEY"),
@@ -2,66 +2,252 @@ package converter
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"io"
 	"log/slog"
-	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/chromedp/cdproto/cdp"
+	cdpio "github.com/chromedp/cdproto/io"
 	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/chromedp"
+	"github.com/psilva1982/rapid_pdf/internal/spool"
 )
 
+const (
+	a4Width  = 8.27  // A4 width in inches
+	a4Height = 11.69 // A4 height in inches
+
+	// streamChunkSize is how much of a printed PDF is read from Chrome at
+	// once.
+	streamChunkSize = 1 << 20
+)
+
+// Options controls how sources are rendered.
+type Options struct {
+	// Timeout bounds the conversion of each individual source.
+	Timeout time.Duration
+	// WaitDelay is how long to let async content settle after page load.
+	WaitDelay time.Duration
+	// SinglePage prints each page on one continuous sheet as tall as the
+	// document instead of paginating it to A4.
+	SinglePage bool
+
+	// Locale overrides the browser locale used for Intl date, number and
+	// currency formatting, e.g. "pt-BR".
+	Locale string
+	// TimezoneID overrides the browser timezone, e.g. "America/Sao_Paulo".
+	TimezoneID string
+	// AcceptLanguage overrides the Accept-Language header and
+	// navigator.language. Defaults to Locale when empty.
+	AcceptLanguage string
+	// Geolocation, when set, is granted to and reported by the page.
+	Geolocation *Geolocation
+
+	// OmitBackground prints without background colors and images, as
+	// Chrome's own print dialog does by default, to save toner.
+	OmitBackground bool
+	// HideLinks prints links like the text around them, without the color
+	// and underline that only make sense on screen.
+	HideLinks bool
+
+	// Snapshot keeps the rendered HTML of every page Chrome prints in
+	// Document.Snapshot.
+	Snapshot bool
+	// SpillThreshold is the size in bytes above which a converted PDF is
+	// buffered in a temporary file instead of memory. Zero uses
+	// spool.DefaultThreshold.
+	SpillThreshold int64
+	// MaxDownloadBytes bounds the size of PDFs downloaded from URLs. Zero
+	// means no limit.
+	MaxDownloadBytes int64
+}
+
+// Document is the result of converting a single source.
+type Document struct {
+	// PDF holds the converted document, in memory unless it is larger than
+	// Options.SpillThreshold.
+	PDF *spool.Buffer
+	// Snapshot is the HTML of the page as rendered by Chrome, when
+	// Options.Snapshot is set. Sources that are not rendered have none.
+	Snapshot []byte
+}
+
+// CloseAll releases every document, including partial results.
+func CloseAll(docs []Document) {
+	for _, doc := range docs {
+		if err := doc.PDF.Close(); err != nil {
+			slog.Warn("failed to release document", "error", err)
+		}
+	}
+}
+
 // ConvertURLToPDF navigates to the given URL using a headless Chrome browser,
-// waits for the page to fully load, and saves the rendered page as a PDF.
-func ConvertURLToPDF(ctx context.Context, url, outputPath string, timeout time.Duration, waitDelay time.Duration) error {
-	slog.Info("converting URL to PDF", "url", url, "output", outputPath)
+// waits for the page to fully load, and returns the rendered page as a PDF.
+// Chrome streams the PDF, so it never has to fit in memory at once.
+func ConvertURLToPDF(ctx context.Context, url string, opts Options) (Document, error) {
+	return convertPage(ctx, url, opts, false)
+}
+
+// convertPage renders url to PDF like ConvertURLToPDF. Isolated pages may
+// only load resources from the origin of url.
+func convertPage(ctx context.Context, url string, opts Options, isolated bool) (Document, error) {
+	slog.Info("converting URL to PDF", "url", url)
 
 	// Create a timeout context for this individual page conversion.
-	taskCtx, cancel := context.WithTimeout(ctx, timeout)
+	taskCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
 	defer cancel()
 
-	var buf []byte
+	doc := Document{PDF: spool.New(opts.SpillThreshold)}
+	var snapshot string
 	err := chromedp.Run(taskCtx,
+		emulate(opts),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			if !isolated {
+				return nil
+			}
+			return isolate(url).Do(ctx)
+		}),
 		chromedp.Navigate(url),
 		// Wait for the body to be visible (page loaded).
 		chromedp.WaitVisible("body", chromedp.ByQuery),
 		// Small delay to let async content settle.
-		chromedp.Sleep(waitDelay),
+		chromedp.Sleep(opts.WaitDelay),
 		chromedp.ActionFunc(func(ctx context.Context) error {
-			var err error
-			buf, _, err = page.PrintToPDF().
-				WithPrintBackground(true).
+			if !opts.HideLinks {
+				return nil
+			}
+			return chromedp.Evaluate(hideLinksJS, nil).Do(ctx)
+		}),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			if !opts.Snapshot {
+				return nil
+			}
+			return chromedp.OuterHTML("html", &snapshot, chromedp.ByQuery).Do(ctx)
+		}),
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			params := page.PrintToPDF().
+				WithPrintBackground(!opts.OmitBackground).
 				WithDisplayHeaderFooter(false).
-				WithPaperWidth(8.27).   // A4 width in inches
-				WithPaperHeight(11.69). // A4 height in inches
-				Do(ctx)
-			return err
+				WithPaperWidth(a4Width).
+				WithPaperHeight(a4Height).
+				WithTransferMode(page.PrintToPDFTransferModeReturnAsStream)
+
+			if opts.SinglePage {
+				height, err := singlePageHeight(ctx, url)
+				if err != nil {
+					return err
+				}
+				params = params.
+					WithPaperHeight(height).
+					WithMarginTop(defaultMargin).
+					WithMarginBottom(defaultMargin)
+			}
+
+			_, stream, err := params.Do(ctx)
+			if err != nil {
+				return err
+			}
+			return readStream(ctx, stream, doc.PDF)
 		}),
 	)
 	if err != nil {
-		return fmt.Errorf("failed to convert %s: %w", url, err)
+		doc.PDF.Close()
+		return Document{}, fmt.Errorf("failed to convert %s: %w", url, err)
 	}
 
-	if err := os.WriteFile(outputPath, buf, 0644); err != nil {
-		return fmt.Errorf("failed to write PDF %s: %w", outputPath, err)
+	if opts.Snapshot {
+		doc.Snapshot = []byte(snapshot)
 	}
 
-	slog.Info("PDF generated successfully", "url", url, "size_bytes", len(buf))
-	return nil
+	slog.Info("PDF generated successfully", "url", url, "size_bytes", doc.PDF.Size())
+	return doc, nil
 }
 
-// ConvertAll processes a slice of URLs and generates a temporary PDF file for
-// each one. It returns the list of generated PDF file paths. The caller is
-// responsible for cleaning up the temporary files.
-func ConvertAll(ctx context.Context, urls []string, timeout time.Duration, waitDelay time.Duration) ([]string, error) {
-	// Create a temporary directory for intermediate PDFs.
-	tmpDir, err := os.MkdirTemp("", "rapid_pdf_*")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+// hideLinksJS adds a style sheet that prints links like plain text. Being
+// added last, it wins over the page's own rules of the same importance.
+const hideLinksJS = `(() => {
+	const style = document.createElement('style');
+	style.textContent = 'a:link, a:visited { color: inherit !important; text-decoration: none !important; }';
+	document.head.appendChild(style);
+})()`
+
+// readStream copies a DevTools stream to w and closes it.
+func readStream(ctx context.Context, handle cdpio.StreamHandle, w io.Writer) error {
+	defer cdpio.Close(handle).Do(ctx)
+
+	for {
+		// Read.Do drops the base64 flag, so the command is executed directly.
+		var chunk cdpio.ReadReturns
+		if err := cdp.Execute(ctx, cdpio.CommandRead, cdpio.Read(handle).WithSize(streamChunkSize), &chunk); err != nil {
+			return fmt.Errorf("failed to read PDF stream: %w", err)
+		}
+
+		data := []byte(chunk.Data)
+		if chunk.Base64encoded {
+			var err error
+			if data, err = base64.StdEncoding.DecodeString(chunk.Data); err != nil {
+				return fmt.Errorf("failed to decode PDF stream: %w", err)
+			}
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to buffer PDF: %w", err)
+		}
+
+		if chunk.EOF {
+			return nil
+		}
 	}
+}
+
+// Source is a single input document of a batch conversion. Exactly one of
+// URL, HTML, Dir or PDFPath is expected to be set.
+type Source struct {
+	// URL is a remote page to render with Chrome.
+	URL string
+	// HTML is a complete HTML document to render with Chrome, such as the
+	// output of the markdown package.
+	HTML []byte
+	// Dir is a local directory holding an HTML document and its assets. It is
+	// served to Chrome over a loopback HTTP server so the page cannot read
+	// other local files, and requests leaving that server are blocked.
+	Dir string
+	// Entry is the HTML file inside Dir to render. Defaults to index.html.
+	Entry string
+	// PDFPath is an existing PDF file that is copied into the batch unchanged.
+	PDFPath string
 
-	slog.Info("starting batch conversion", "url_count", len(urls), "tmp_dir", tmpDir)
+	// Label is a caller-supplied title for the source, used for its bookmark
+	// instead of the page <title>.
+	Label string
+	// Name identifies the source in logs and errors, e.g. the original name
+	// of an uploaded file. Defaults to the URL or file name.
+	Name string
+}
+
+// URLSources wraps a list of URLs as conversion sources.
+func URLSources(urls []string) []Source {
+	sources := make([]Source, len(urls))
+	for i, u := range urls {
+		sources[i] = Source{URL: u}
+	}
+	return sources
+}
+
+// ConvertAll converts a slice of URLs, one document each. URLs that already
+// serve a PDF are downloaded unchanged instead of being printed. The caller
+// is responsible for releasing the documents with CloseAll.
+func ConvertAll(ctx context.Context, urls []string, opts Options) ([]Document, error) {
+	return ConvertSources(ctx, URLSources(urls), opts)
+}
+
+// ConvertSources converts every source to a PDF document, in order. On error
+// it also returns the documents converted so far. The caller is responsible
+// for releasing them with CloseAll.
+func ConvertSources(ctx context.Context, sources []Source, opts Options) ([]Document, error) {
+	slog.Info("starting batch conversion", "source_count", len(sources))
 
 	// Create a single browser context to reuse across all pages.
 	allocCtx, allocCancel := chromedp.NewExecAllocator(ctx,
@@ -75,25 +261,93 @@ func ConvertAll(ctx context.Context, urls []string, timeout time.Duration, waitD
 	)
 	defer allocCancel()
 
-	var pdfPaths []string
+	var docs []Document
+
+	for i, src := range sources {
+		doc, err := convertSource(ctx, allocCtx, src, opts)
+		if err != nil {
+			slog.Error("failed to convert source", "source", src.String(), "error", err)
+			return docs, fmt.Errorf("error on source #%d (%s): %w", i+1, src, err)
+		}
+
+		docs = append(docs, doc)
 
-	for i, url := range urls {
-		// Each URL gets its own browser context (isolated cookies/cache).
-		taskCtx, taskCancel := chromedp.NewContext(allocCtx)
+		slog.Info("progress", "completed", i+1, "total", len(sources))
+	}
 
-		outputPath := filepath.Join(tmpDir, fmt.Sprintf("page_%03d.pdf", i+1))
+	return docs, nil
+}
 
-		if err := ConvertURLToPDF(taskCtx, url, outputPath, timeout, waitDelay); err != nil {
-			taskCancel()
-			slog.Error("failed to convert URL", "url", url, "error", err)
-			return pdfPaths, fmt.Errorf("error on URL #%d (%s): %w", i+1, url, err)
+// convertSource converts a single source to a PDF document.
+func convertSource(ctx, allocCtx context.Context, src Source, opts Options) (Document, error) {
+	if src.PDFPath != "" {
+		doc := Document{PDF: spool.New(opts.SpillThreshold)}
+		if err := copyPDF(src.PDFPath, doc.PDF); err != nil {
+			doc.PDF.Close()
+			return Document{}, err
 		}
+		return doc, nil
+	}
 
-		pdfPaths = append(pdfPaths, outputPath)
-		taskCancel()
+	// Each source gets its own browser context (isolated cookies/cache).
+	taskCtx, taskCancel := chromedp.NewContext(allocCtx)
+	defer taskCancel()
 
-		slog.Info("progress", "completed", i+1, "total", len(urls))
+	// Local documents are served over loopback; inline HTML straight from
+	// memory.
+	if src.HTML != nil || src.Dir != "" {
+		var pageURL string
+		var stop func()
+		var err error
+		if src.HTML != nil {
+			pageURL, stop, err = serveHTML(src.HTML)
+		} else {
+			pageURL, stop, err = serveDir(src.Dir, src.Entry)
+		}
+		if err != nil {
+			return Document{}, err
+		}
+		defer stop()
+
+		return convertPage(taskCtx, pageURL, opts, true)
 	}
 
-	return pdfPaths, nil
+	// URLs that already serve a PDF are downloaded as-is; printing them
+	// through Chrome would only capture its PDF viewer. Obvious web pages
+	// skip the probe and its extra round trip.
+	var isPDF bool
+	if !IsHTMLURL(src.URL) {
+		var err error
+		isPDF, err = IsPDFURL(ctx, src.URL, opts.Timeout)
+		if err != nil {
+			slog.Warn("PDF probe failed, rendering with Chrome", "url", src.URL, "error", err)
+		}
+	}
+
+	if isPDF {
+		doc := Document{PDF: spool.New(opts.SpillThreshold)}
+		if err := DownloadPDF(ctx, src.URL, doc.PDF, opts.Timeout, opts.MaxDownloadBytes); err != nil {
+			doc.PDF.Close()
+			return Document{}, err
+		}
+		return doc, nil
+	}
+	return ConvertURLToPDF(taskCtx, src.URL, opts)
+}
+
+// String returns a short human-readable description of the source for logs
+// and error messages.
+func (s Source) String() string {
+	switch {
+	case s.Name != "":
+		return s.Name
+	case s.PDFPath != "":
+		return filepath.Base(s.PDFPath)
+	case s.HTML != nil:
+		return "inline HTML"
+	case s.Dir != "":
+		return filepath.Base(s.Dir)
+	default:
+		return s.URL
+	}
 }
@@ -0,0 +1,103 @@
+package converter
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+)
+
+// coverLogoTypes are the logo formats Chrome can draw on a cover page.
+var coverLogoTypes = map[string]bool{
+	"image/png":     true,
+	"image/jpeg":    true,
+	"image/gif":     true,
+	"image/webp":    true,
+	"image/svg+xml": true,
+}
+
+// Cover holds the content of a cover page.
+type Cover struct {
+	Title    string
+	Subtitle string
+	Author   string
+	// Date is printed as is, e.g. "March 2026". Defaults to today's date
+	// as YYYY-MM-DD.
+	Date string
+	// Logo is a PNG, JPEG, GIF, WebP or SVG image shown above the title.
+	Logo []byte
+	// Template is a custom html/template document rendered instead of the
+	// built-in one. It receives the fields above, with Logo as a data URL.
+	Template string
+}
+
+// Validate checks that the logo is a supported image and that the custom
+// template parses.
+func (c Cover) Validate() error {
+	if _, err := c.logoURL(); err != nil {
+		return err
+	}
+	if _, err := c.template(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// logoURL returns the logo as a data URL, or an empty URL without a logo.
+func (c Cover) logoURL() (template.URL, error) {
+	if len(c.Logo) == 0 {
+		return "", nil
+	}
+
+	contentType := http.DetectContentType(c.Logo)
+	if contentType != "image/svg+xml" && bytes.Contains(c.Logo, []byte("<svg")) {
+		contentType = "image/svg+xml"
+	}
+	if !coverLogoTypes[contentType] {
+		return "", fmt.Errorf("unsupported logo type %s", contentType)
+	}
+
+	return template.URL("data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(c.Logo)), nil
+}
+
+// template returns the custom template, or the built-in one when unset.
+func (c Cover) template() (*template.Template, error) {
+	if c.Template == "" {
+		return templates.Lookup("cover.html"), nil
+	}
+
+	tmpl, err := template.New("cover").Parse(c.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cover template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderCover renders the cover page. The result is a complete HTML document
+// to be printed as an HTML Source.
+func RenderCover(c Cover) ([]byte, error) {
+	logo, err := c.logoURL()
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := c.template()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, map[string]any{
+		"Title":    c.Title,
+		"Subtitle": c.Subtitle,
+		"Author":   c.Author,
+		"Date":     cmp.Or(c.Date, time.Now().Format(time.DateOnly)),
+		"Logo":     logo,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render cover page: %w", err)
+	}
+	return buf.Bytes(), nil
+}
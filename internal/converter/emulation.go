@@ -0,0 +1,102 @@
+package converter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+	// The runtime image ships without a zoneinfo database.
+	_ "time/tzdata"
+
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/chromedp"
+	"golang.org/x/text/language"
+)
+
+// Geolocation is the position reported to pages that use the Geolocation API.
+type Geolocation struct {
+	Latitude  float64
+	Longitude float64
+	// Accuracy is the radius of uncertainty in meters.
+	Accuracy float64
+}
+
+// ValidateEmulation checks the locale and timezone overrides of o, so bad
+// input is rejected before Chrome starts instead of failing the conversion.
+func (o Options) ValidateEmulation() error {
+	if o.Locale != "" {
+		if _, err := language.Parse(o.Locale); err != nil {
+			return fmt.Errorf("invalid locale %q: %w", o.Locale, err)
+		}
+	}
+	if o.TimezoneID != "" {
+		if _, err := time.LoadLocation(o.TimezoneID); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", o.TimezoneID, err)
+		}
+	}
+	return nil
+}
+
+// emulate applies the locale, timezone, Accept-Language and geolocation
+// overrides from opts to the current tab. It must run before navigation so
+// the very first request and script already see the emulated environment.
+func emulate(opts Options) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		if opts.Locale != "" {
+			if err := emulation.SetLocaleOverride().WithLocale(opts.Locale).Do(ctx); err != nil {
+				return fmt.Errorf("failed to set locale %q: %w", opts.Locale, err)
+			}
+		}
+
+		if opts.TimezoneID != "" {
+			if err := emulation.SetTimezoneOverride(opts.TimezoneID).Do(ctx); err != nil {
+				return fmt.Errorf("failed to set timezone %q: %w", opts.TimezoneID, err)
+			}
+		}
+
+		// Accept-Language drives both the request header and navigator.language;
+		// it follows the locale unless the caller sets it explicitly.
+		acceptLanguage := opts.AcceptLanguage
+		if acceptLanguage == "" {
+			acceptLanguage = opts.Locale
+		}
+		if acceptLanguage != "" {
+			_, _, _, userAgent, _, err := browser.GetVersion().Do(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to read user agent: %w", err)
+			}
+			err = emulation.SetUserAgentOverride(userAgent).
+				WithAcceptLanguage(acceptLanguage).
+				Do(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to set Accept-Language %q: %w", acceptLanguage, err)
+			}
+		}
+
+		if geo := opts.Geolocation; geo != nil {
+			err := browser.GrantPermissions([]browser.PermissionType{browser.PermissionTypeGeolocation}).Do(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to grant geolocation permission: %w", err)
+			}
+			err = emulation.SetGeolocationOverride().
+				WithLatitude(geo.Latitude).
+				WithLongitude(geo.Longitude).
+				WithAccuracy(geo.Accuracy).
+				Do(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to set geolocation: %w", err)
+			}
+		}
+
+		if opts.Locale != "" || opts.TimezoneID != "" || acceptLanguage != "" || opts.Geolocation != nil {
+			slog.Info("emulation applied",
+				"locale", opts.Locale,
+				"timezone", opts.TimezoneID,
+				"accept_language", acceptLanguage,
+				"geolocation", opts.Geolocation != nil,
+			)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,24 @@
+package converter
+
+import "testing"
+
+func TestValidateEmulation(t *testing.T) {
+	tests := []struct {
+		locale, timezone string
+		wantErr          bool
+	}{
+		{"", "", false},
+		{"pt-BR", "America/Sao_Paulo", false},
+		{"en_US", "UTC", false},
+		{"de", "Europe/Berlin", false},
+		{"pt_BR!!", "", true},
+		{"", "Mars/Olympus", true},
+		{"", "../etc/passwd", true},
+	}
+	for _, tt := range tests {
+		err := Options{Locale: tt.locale, TimezoneID: tt.timezone}.ValidateEmulation()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidateEmulation(%q, %q) = %v, want error %v", tt.locale, tt.timezone, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package converter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// pdfMagic is the header every PDF file starts with.
+var pdfMagic = []byte("%PDF-")
+
+// htmlExtensions are the path extensions of static web pages, which are
+// rendered without probing for a PDF first. Server pages such as .php are
+// left out, as download.php?id=... often serves a PDF.
+var htmlExtensions = map[string]bool{
+	".htm": true, ".html": true, ".xhtml": true, ".shtml": true,
+}
+
+// IsHTMLURL reports whether the URL clearly points to a web page: a site
+// root, a directory or a file with a static HTML extension, without a query
+// string. Other URLs may serve anything, e.g. a PDF from /reports/123 or
+// /download?id=123.
+func IsHTMLURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery != "" {
+		return false
+	}
+	if u.Path == "" || strings.HasSuffix(u.Path, "/") {
+		return true
+	}
+	return htmlExtensions[strings.ToLower(path.Ext(u.Path))]
+}
+
+// IsPDFURL probes the given URL and reports whether it serves a PDF document.
+// It first issues a HEAD request and falls back to a ranged GET for servers
+// that reject HEAD or omit the Content-Type header.
+func IsPDFURL(ctx context.Context, url string, timeout time.Duration) (bool, error) {
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	contentType, err := probeContentType(probeCtx, http.MethodHead, url)
+	if err != nil || contentType == "" {
+		contentType, err = probeContentType(probeCtx, http.MethodGet, url)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false, nil
+	}
+
+	return mediaType == "application/pdf", nil
+}
+
+// probeContentType sends a single request with the given method and returns
+// the Content-Type of the response. GET requests only ask for the first byte
+// so the body is never downloaded just to inspect the headers.
+func probeContentType(ctx context.Context, method, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build %s request: %w", method, err)
+	}
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%s %s failed: %w", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("%s %s returned status %d", method, url, resp.StatusCode)
+	}
+
+	return resp.Header.Get("Content-Type"), nil
+}
+
+// DownloadPDF fetches a URL that already serves a PDF and copies the file to
+// w unchanged, so it can be merged alongside Chrome-rendered pages. Files
+// larger than maxBytes are rejected; zero means no limit.
+func DownloadPDF(ctx context.Context, url string, w io.Writer, timeout time.Duration, maxBytes int64) error {
+	slog.Info("downloading PDF", "url", url)
+
+	taskCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(taskCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request for %s: %w", url, err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: status %d", url, resp.StatusCode)
+	}
+	if maxBytes > 0 && resp.ContentLength > maxBytes {
+		return fmt.Errorf("%s is larger than the limit of %d bytes", url, maxBytes)
+	}
+
+	var body io.Reader = resp.Body
+	if maxBytes > 0 {
+		// One byte over the limit tells a file of exactly maxBytes from a
+		// larger one.
+		body = io.LimitReader(resp.Body, maxBytes+1)
+	}
+	n, err := copyChecked(w, body)
+	if err == nil && maxBytes > 0 && n > maxBytes {
+		return fmt.Errorf("%s is larger than the limit of %d bytes", url, maxBytes)
+	}
+	if errors.Is(err, errNotPDF) {
+		return fmt.Errorf("response from %s is not a valid PDF", url)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", url, err)
+	}
+
+	slog.Info("PDF downloaded successfully", "url", url, "size_bytes", n)
+	return nil
+}
+
+// copyPDF copies an existing PDF file to w unchanged after checking that it
+// really is a PDF.
+func copyPDF(inputPath string, w io.Writer) error {
+	f, err := os.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF %s: %w", inputPath, err)
+	}
+	defer f.Close()
+
+	_, err = copyChecked(w, f)
+	if errors.Is(err, errNotPDF) {
+		return fmt.Errorf("%s is not a valid PDF", filepath.Base(inputPath))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read PDF %s: %w", inputPath, err)
+	}
+	return nil
+}
+
+// errNotPDF is returned by copyChecked for content without a PDF header.
+var errNotPDF = errors.New("not a PDF")
+
+// copyChecked copies r to w after checking that it starts with the PDF
+// header, and returns the number of bytes copied.
+func copyChecked(w io.Writer, r io.Reader) (int64, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(len(pdfMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+	if !bytes.Equal(head, pdfMagic) {
+		return 0, errNotPDF
+	}
+	return io.Copy(w, br)
+}
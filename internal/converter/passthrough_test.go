@@ -0,0 +1,76 @@
+package converter
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIsHTMLURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com", true},
+		{"https://example.com/", true},
+		{"https://example.com/docs/", true},
+		{"https://example.com/about.html", true},
+		{"https://example.com/ABOUT.HTM", true},
+		{"https://example.com/page.xhtml", true},
+		{"https://example.com/reports/123", false},
+		{"https://example.com/report.pdf", false},
+		{"https://example.com/download.php", false},
+		{"https://example.com/download.php?id=7", false},
+		{"https://example.com/file.aspx?id=7", false},
+		{"https://example.com/view.jsp", false},
+		{"https://example.com/?download=1", false},
+		{"https://example.com/page.html?format=pdf", false},
+		{"://bad", false},
+	}
+	for _, tt := range tests {
+		if got := IsHTMLURL(tt.url); got != tt.want {
+			t.Errorf("IsHTMLURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestIsPDFURL(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/download.php", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf; name=report.pdf")
+		w.Write([]byte("%PDF-1.7"))
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html></html>"))
+	})
+	mux.HandleFunc("/get-only", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		http.ServeContent(w, r, "report.pdf", time.Time{}, bytes.NewReader([]byte("%PDF-1.7")))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		path    string
+		want    bool
+		wantErr bool
+	}{
+		{"/download.php?id=7", true, false},
+		{"/page", false, false},
+		{"/get-only", true, false},
+		{"/missing", false, true},
+	}
+	for _, tt := range tests {
+		got, err := IsPDFURL(context.Background(), srv.URL+tt.path, 5*time.Second)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("IsPDFURL(%s) = %v, %v; want %v, error %v", tt.path, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
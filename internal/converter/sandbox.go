@@ -0,0 +1,139 @@
+package converter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// defaultEntry is the HTML file rendered when a Source does not name one.
+const defaultEntry = "index.html"
+
+// serveDir starts a loopback HTTP server that exposes only the files inside
+// dir, without directory listings, and returns the URL of the entry
+// document. Serving local HTML over HTTP instead of file:// keeps the page
+// from reading files outside of dir; isolate blocks the network. The
+// returned stop function shuts the server down.
+func serveDir(dir, entry string) (string, func(), error) {
+	if entry == "" {
+		entry = defaultEntry
+	}
+	return serve(http.FileServer(noListing{http.Dir(dir)}), entry)
+}
+
+// noListing is a file system whose directories cannot be listed. Directories
+// with an index.html still serve it.
+type noListing struct {
+	http.FileSystem
+}
+
+// Open opens a file, or a directory only when it has an index.html.
+func (nl noListing) Open(name string) (http.File, error) {
+	f, err := nl.FileSystem.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := f.Stat(); err == nil && info.IsDir() {
+		index, err := nl.FileSystem.Open(path.Join(name, "index.html"))
+		if err != nil {
+			f.Close()
+			return nil, fs.ErrNotExist
+		}
+		index.Close()
+	}
+	return f, nil
+}
+
+// serveHTML starts a loopback HTTP server that exposes a single inline HTML
+// document from memory, like serveDir does for a directory.
+func serveHTML(html []byte) (string, func(), error) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/"+defaultEntry {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(html)
+	})
+	return serve(handler, defaultEntry)
+}
+
+// isolate fails every request of the page that leaves origin, such as
+// requests to other local ports or to the network, so a local document can
+// only load its own assets. data: and blob: URLs never reach the network and
+// are not affected. It must run before navigation.
+func isolate(origin string) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		chromedp.ListenTarget(ctx, func(ev any) {
+			e, ok := ev.(*fetch.EventRequestPaused)
+			if !ok {
+				return
+			}
+			// Commands cannot be sent from the listener itself.
+			go func() {
+				c := chromedp.FromContext(ctx)
+				execCtx := cdp.WithExecutor(ctx, c.Target)
+
+				var err error
+				if sameOrigin(e.Request.URL, origin) {
+					err = fetch.ContinueRequest(e.RequestID).Do(execCtx)
+				} else {
+					slog.Warn("blocked request leaving the local document", "url", e.Request.URL)
+					err = fetch.FailRequest(e.RequestID, network.ErrorReasonBlockedByClient).Do(execCtx)
+				}
+				if err != nil && ctx.Err() == nil {
+					slog.Warn("failed to resolve intercepted request", "url", e.Request.URL, "error", err)
+				}
+			}()
+		})
+		return fetch.Enable().Do(ctx)
+	}
+}
+
+// sameOrigin reports whether rawURL has the scheme and host of origin.
+func sameOrigin(rawURL, origin string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	o, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == o.Scheme && u.Host == o.Host
+}
+
+// serve starts a loopback HTTP server with handler and returns the URL of the
+// entry document along with a function that shuts the server down.
+func serve(handler http.Handler, entry string) (string, func(), error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to start local file server: %w", err)
+	}
+
+	srv := &http.Server{Handler: handler}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Warn("local file server stopped", "error", err)
+		}
+	}()
+
+	pageURL := url.URL{
+		Scheme: "http",
+		Host:   ln.Addr().String(),
+		Path:   path.Join("/", entry),
+	}
+
+	return pageURL.String(), func() { srv.Close() }, nil
+}
@@ -0,0 +1,57 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+)
+
+// Separator holds the content of a page announcing the next source.
+type Separator struct {
+	Title string
+	// Number is the position of the source, starting at 1, out of Total.
+	Number int
+	Total  int
+	// Template is a custom html/template document rendered instead of the
+	// built-in one. It receives the fields above.
+	Template string
+}
+
+// Validate checks that the custom template parses.
+func (s Separator) Validate() error {
+	_, err := s.template()
+	return err
+}
+
+// template returns the custom template, or the built-in one when unset.
+func (s Separator) template() (*template.Template, error) {
+	if s.Template == "" {
+		return templates.Lookup("separator.html"), nil
+	}
+
+	tmpl, err := template.New("separator").Parse(s.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid separator template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// RenderSeparator renders a separator page. The result is a complete HTML
+// document to be printed as an HTML Source.
+func RenderSeparator(s Separator) ([]byte, error) {
+	tmpl, err := s.template()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, map[string]any{
+		"Title":  s.Title,
+		"Number": s.Number,
+		"Total":  s.Total,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render separator page: %w", err)
+	}
+	return buf.Bytes(), nil
+}
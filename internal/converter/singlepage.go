@@ -0,0 +1,62 @@
+package converter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/chromedp"
+)
+
+const (
+	// defaultMargin mirrors Chrome's default print margin of 1cm, in inches.
+	defaultMargin = 0.4
+	// cssPixelsPerInch is the fixed CSS reference resolution.
+	cssPixelsPerInch = 96
+	// maxPaperHeight is the largest page height, in inches, a PDF page can
+	// have (14400 user units). Taller documents are split into pages of
+	// this height instead.
+	maxPaperHeight = 200.0
+)
+
+// documentHeightJS returns the full scroll height of the document in CSS pixels.
+const documentHeightJS = `Math.max(
+	document.documentElement.scrollHeight,
+	document.body ? document.body.scrollHeight : 0
+)`
+
+// singlePageHeight measures the rendered document laid out at the printable
+// A4 width with print media styles applied, and returns the paper height in
+// inches needed to print it on a single page.
+func singlePageHeight(ctx context.Context, url string) (float64, error) {
+	printableWidth := int64(math.Round((a4Width - 2*defaultMargin) * cssPixelsPerInch))
+
+	var heightPx float64
+	err := chromedp.Tasks{
+		emulation.SetEmulatedMedia().WithMedia("print"),
+		emulation.SetDeviceMetricsOverride(printableWidth, int64(math.Round(a4Height*cssPixelsPerInch)), 1, false),
+		chromedp.Evaluate(documentHeightJS, &heightPx),
+		emulation.ClearDeviceMetricsOverride(),
+	}.Do(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure document height: %w", err)
+	}
+
+	// Round up and leave a pixel of slack so sub-pixel layout differences
+	// between the viewport and the print layout never spill onto a new page.
+	height := (math.Ceil(heightPx)+1)/cssPixelsPerInch + 2*defaultMargin
+
+	if height > maxPaperHeight {
+		slog.Warn("document too tall for a single page, splitting",
+			"url", url,
+			"height_in", fmt.Sprintf("%.2f", height),
+			"max_height_in", maxPaperHeight,
+		)
+		return maxPaperHeight, nil
+	}
+
+	slog.Info("printing as single page", "url", url, "height_in", fmt.Sprintf("%.2f", height))
+	return height, nil
+}
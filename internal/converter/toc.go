@@ -0,0 +1,36 @@
+package converter
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+)
+
+//go:embed templates/*.html
+var templateFiles embed.FS
+
+var templates = template.Must(template.ParseFS(templateFiles, "templates/*.html"))
+
+// TOCEntry is a single line of the table of contents.
+type TOCEntry struct {
+	Title string
+	// Page is the page number the entry starts on in the final document.
+	Page int
+	// Link is the URL the entry links to.
+	Link string
+}
+
+// RenderTOC renders the table of contents page from the built-in template.
+// The result is a complete HTML document to be printed as an HTML Source.
+func RenderTOC(title string, entries []TOCEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	err := templates.ExecuteTemplate(&buf, "toc.html", map[string]any{
+		"Title":   title,
+		"Entries": entries,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render table of contents: %w", err)
+	}
+	return buf.Bytes(), nil
+}
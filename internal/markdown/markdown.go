@@ -0,0 +1,148 @@
+package markdown
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// DefaultTheme is the built-in theme used when a request does not pick one.
+const DefaultTheme = "github"
+
+//go:embed themes/*.css
+var themeFiles embed.FS
+
+// codeStyles maps each built-in theme to the chroma style used to highlight
+// fenced code blocks.
+var codeStyles = map[string]string{
+	"github":  "github",
+	"minimal": "bw",
+	"serif":   "tango",
+}
+
+// Options controls how a Markdown document is turned into HTML.
+type Options struct {
+	// Theme is the name of a built-in stylesheet. Defaults to DefaultTheme.
+	Theme string
+	// Stylesheet is custom CSS appended after the theme, so it can override
+	// any of its rules.
+	Stylesheet string
+	// Title is used as the document <title>. Defaults to the text of the
+	// first top-level heading.
+	Title string
+}
+
+// documentTemplate wraps the rendered Markdown body into a printable page.
+var documentTemplate = template.Must(template.New("document").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+{{.ThemeCSS}}
+</style>
+{{- if .CustomCSS}}
+<style>
+{{.CustomCSS}}
+</style>
+{{- end}}
+</head>
+<body>
+<article class="markdown-body">
+{{.Body}}
+</article>
+</body>
+</html>
+`))
+
+// Themes returns the names of the built-in themes.
+func Themes() []string {
+	names := make([]string, 0, len(codeStyles))
+	for name := range codeStyles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsTheme reports whether name is a built-in theme.
+func IsTheme(name string) bool {
+	_, ok := codeStyles[name]
+	return ok
+}
+
+// Render converts GitHub Flavored Markdown (tables, task lists,
+// strikethrough, autolinks) with highlighted code blocks into a standalone
+// HTML document ready to be printed by the converter.
+func Render(src []byte, opts Options) ([]byte, error) {
+	theme := opts.Theme
+	if theme == "" {
+		theme = DefaultTheme
+	}
+	if !IsTheme(theme) {
+		return nil, fmt.Errorf("unknown markdown theme %q", theme)
+	}
+
+	themeCSS, err := themeFiles.ReadFile("themes/" + theme + ".css")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load theme %s: %w", theme, err)
+	}
+
+	md := goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			highlighting.NewHighlighting(
+				highlighting.WithStyle(codeStyles[theme]),
+			),
+		),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		goldmark.WithRendererOptions(gmhtml.WithUnsafe()),
+	)
+
+	root := md.Parser().Parse(text.NewReader(src))
+
+	var body bytes.Buffer
+	if err := md.Renderer().Render(&body, src, root); err != nil {
+		return nil, fmt.Errorf("failed to render markdown: %w", err)
+	}
+
+	title := opts.Title
+	if title == "" {
+		title = firstHeading(root, src)
+	}
+
+	var doc bytes.Buffer
+	err = documentTemplate.Execute(&doc, map[string]any{
+		"Title":     title,
+		"ThemeCSS":  template.CSS(themeCSS),
+		"CustomCSS": template.CSS(opts.Stylesheet),
+		"Body":      template.HTML(body.String()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build markdown document: %w", err)
+	}
+
+	return doc.Bytes(), nil
+}
+
+// firstHeading returns the raw text of the first level-one heading of the
+// document, or an empty string when there is none.
+func firstHeading(root ast.Node, src []byte) string {
+	for n := root.FirstChild(); n != nil; n = n.NextSibling() {
+		if h, ok := n.(*ast.Heading); ok && h.Level == 1 {
+			return strings.TrimSpace(string(h.Lines().Value(src)))
+		}
+	}
+	return ""
+}
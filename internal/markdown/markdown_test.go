@@ -0,0 +1,93 @@
+package markdown
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+const codeSample = "```go\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n```\n"
+
+// fragment returns the rendered Markdown body of a document made by Render.
+func fragment(t *testing.T, doc []byte) []byte {
+	t.Helper()
+	const prefix, suffix = `<article class="markdown-body">`, `</article>`
+	start := bytes.Index(doc, []byte(prefix))
+	end := bytes.LastIndex(doc, []byte(suffix))
+	if start < 0 || end < start {
+		t.Fatalf("document has no markdown body:\n%s", doc)
+	}
+	return bytes.TrimSpace(doc[start+len(prefix) : end])
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name  string
+		theme string
+		src   string
+	}{
+		{"table", "", "| Name | Qty |\n|:-----|----:|\n| pen  | 2   |\n| ink  | 10  |\n"},
+		{"gfm", "", "# Notes\n\n- [x] done\n- [ ] todo\n\n~~old~~ see https://example.com\n"},
+		{"raw_html", "", "<div class=\"note\">kept <b>as is</b></div>\n\nText with <span style=\"color:red\">inline</span> HTML.\n"},
+		{"code_github", "github", codeSample},
+		{"code_minimal", "minimal", codeSample},
+		{"code_serif", "serif", codeSample},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Render([]byte(tt.src), Options{Theme: tt.theme})
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			got := fragment(t, doc)
+
+			golden := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, append(got, '\n'), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, bytes.TrimSpace(want)) {
+				t.Errorf("rendered HTML differs from %s:\n got: %s\nwant: %s", golden, got, want)
+			}
+		})
+	}
+}
+
+func TestRenderThemes(t *testing.T) {
+	for _, theme := range Themes() {
+		t.Run(theme, func(t *testing.T) {
+			css, err := themeFiles.ReadFile("themes/" + theme + ".css")
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := Render([]byte("# Title\n"), Options{Theme: theme, Stylesheet: "h1 { color: red; }"})
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if !bytes.Contains(doc, bytes.TrimSpace(css)) {
+				t.Error("document does not embed the theme stylesheet")
+			}
+			// The custom stylesheet comes after the theme so it wins.
+			if i, j := bytes.Index(doc, bytes.TrimSpace(css)), bytes.Index(doc, []byte("h1 { color: red; }")); j < i {
+				t.Error("custom stylesheet is not placed after the theme")
+			}
+			if !strings.Contains(string(doc), "<title>Title</title>") {
+				t.Error("document title is not taken from the first heading")
+			}
+		})
+	}
+
+	if _, err := Render([]byte("# Title\n"), Options{Theme: "neon"}); err == nil {
+		t.Error("unknown theme succeeded")
+	}
+}
@@ -0,0 +1,84 @@
+package merger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// Attachment is a file embedded into a PDF, listed by viewers in their
+// attachments panel.
+type Attachment struct {
+	// Name is the file name shown by viewers, e.g. "request.json".
+	Name        string
+	Description string
+	Data        []byte
+}
+
+// Validate checks that the attachment has a usable file name.
+func (a Attachment) Validate() error {
+	if a.Name == "" {
+		return fmt.Errorf("attachment name is required")
+	}
+	if a.Name == "." || a.Name == ".." || strings.ContainsAny(a.Name, `/\`) {
+		return fmt.Errorf("invalid attachment name %q", a.Name)
+	}
+	return nil
+}
+
+// AddAttachments embeds the attachments into the PDF read from rs and writes
+// the result to w. Names must be unique.
+func AddAttachments(rs io.ReadSeeker, w io.Writer, attachments []Attachment) error {
+	if len(attachments) == 0 {
+		return passThrough(rs, w)
+	}
+
+	seen := make(map[string]bool, len(attachments))
+	for _, a := range attachments {
+		if err := a.Validate(); err != nil {
+			return err
+		}
+		if seen[a.Name] {
+			return fmt.Errorf("duplicate attachment name %q", a.Name)
+		}
+		seen[a.Name] = true
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.ADDATTACHMENTS
+	conf.ValidationMode = model.ValidationRelaxed
+
+	ctx, err := readPDF(rs, conf)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	now := time.Now()
+	var size int
+	for _, a := range attachments {
+		err := ctx.AddAttachment(model.Attachment{
+			Reader:   bytes.NewReader(a.Data),
+			ID:       a.Name,
+			FileName: a.Name,
+			Desc:     a.Description,
+			ModTime:  &now,
+		}, false)
+		if err != nil {
+			return fmt.Errorf("failed to attach %s: %w", a.Name, err)
+		}
+		size += len(a.Data)
+	}
+
+	if err := api.WriteContext(ctx, w); err != nil {
+		return fmt.Errorf("failed to write PDF with attachments: %w", err)
+	}
+
+	slog.Info("attachments embedded", "count", len(attachments), "size_bytes", size)
+	return nil
+}
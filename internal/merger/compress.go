@@ -0,0 +1,204 @@
+package merger
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"log/slog"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"golang.org/x/image/draw"
+)
+
+// Compression levels accepted by Compress.
+const (
+	CompressionNone       = "none"
+	CompressionStandard   = "standard"
+	CompressionAggressive = "aggressive"
+)
+
+// compressionProfile holds the image settings of a compression level.
+type compressionProfile struct {
+	// dpi is the resolution images are downsampled to, relative to the
+	// size of the page they are drawn on.
+	dpi int
+	// quality is the JPEG quality of recompressed images.
+	quality int
+	// recompress re-encodes images that are already small enough.
+	recompress bool
+}
+
+var compressionProfiles = map[string]compressionProfile{
+	CompressionStandard:   {dpi: 150, quality: 85},
+	CompressionAggressive: {dpi: 96, quality: 60, recompress: true},
+}
+
+// CompressionLevels returns the accepted compression levels.
+func CompressionLevels() []string {
+	return []string{CompressionNone, CompressionStandard, CompressionAggressive}
+}
+
+// IsCompressionLevel reports whether level is a known compression level. The
+// empty string means CompressionNone.
+func IsCompressionLevel(level string) bool {
+	_, ok := compressionProfiles[level]
+	return ok || level == "" || level == CompressionNone
+}
+
+// Compress shrinks the PDF read from rs and writes the result to w: pdfcpu
+// optimization removes duplicate fonts and resources, then raster images
+// larger than their page needs at the target resolution are downsampled and
+// recompressed as JPEG. A dpi of zero uses the default resolution of the
+// level.
+func Compress(rs io.ReadSeeker, w io.Writer, level string, dpi int) error {
+	profile, ok := compressionProfiles[level]
+	if !ok {
+		if level == "" || level == CompressionNone {
+			return passThrough(rs, w)
+		}
+		return fmt.Errorf("unknown compression level %q", level)
+	}
+	if dpi > 0 {
+		profile.dpi = dpi
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.OPTIMIZE
+	conf.ValidationMode = model.ValidationRelaxed
+
+	ctx, err := readPDF(rs, conf)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	if err := api.OptimizeContext(ctx); err != nil {
+		return fmt.Errorf("failed to optimize PDF: %w", err)
+	}
+
+	images, err := downsampleImages(ctx, profile)
+	if err != nil {
+		return err
+	}
+
+	if err := api.WriteContext(ctx, w); err != nil {
+		return fmt.Errorf("failed to write compressed PDF: %w", err)
+	}
+
+	slog.Info("PDF compressed", "level", level, "dpi", profile.dpi, "images_resampled", images)
+	return nil
+}
+
+// downsampleImages replaces every eligible image whose re-encoded version is
+// smaller than the original and returns the number of replaced images.
+func downsampleImages(ctx *model.Context, profile compressionProfile) (int, error) {
+	dims, err := ctx.PageDims()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read page sizes: %w", err)
+	}
+
+	done := map[int]bool{}
+	replaced := 0
+	for pageNr := 1; pageNr <= ctx.PageCount; pageNr++ {
+		// An image cannot be drawn larger than the page it is placed on.
+		page := dims[pageNr-1]
+		maxW := int(page.Width / 72 * float64(profile.dpi))
+		maxH := int(page.Height / 72 * float64(profile.dpi))
+
+		for _, objNr := range pdfcpu.ImageObjNrs(ctx, pageNr) {
+			if done[objNr] {
+				continue
+			}
+			done[objNr] = true
+
+			ok, err := downsampleImage(ctx, objNr, pageNr, maxW, maxH, profile)
+			if err != nil {
+				slog.Warn("skipping image", "obj", objNr, "page", pageNr, "error", err)
+				continue
+			}
+			if ok {
+				replaced++
+			}
+		}
+	}
+
+	return replaced, nil
+}
+
+// downsampleImage resamples a single image object to fit within maxW x maxH
+// and reports whether it was replaced. Masked, transparent, indexed and
+// non 8-bit images are left alone, as re-encoding them would lose
+// information.
+func downsampleImage(ctx *model.Context, objNr, pageNr, maxW, maxH int, profile compressionProfile) (bool, error) {
+	imageObj := ctx.Optimize.ImageObjects[objNr]
+	sd := imageObj.ImageDict
+	resourceID := imageObj.ResourceNames[pageNr-1]
+
+	stub, err := pdfcpu.ExtractImage(ctx, sd, false, resourceID, objNr, true)
+	if err != nil || stub == nil {
+		return false, err
+	}
+	if stub.IsImgMask || stub.HasImgMask || stub.HasSMask || stub.Bpc != 8 || (stub.Comp != 1 && stub.Comp != 3) {
+		return false, nil
+	}
+	switch stub.Cs {
+	case model.DeviceRGBCS, model.DeviceGrayCS, model.ICCBasedCS:
+	default:
+		return false, nil
+	}
+
+	scale := min(1, float64(maxW)/float64(stub.Width), float64(maxH)/float64(stub.Height))
+	if scale == 1 && !profile.recompress {
+		return false, nil
+	}
+
+	original := sd.Raw
+	extracted, err := pdfcpu.ExtractImage(ctx, sd, false, resourceID, objNr, false)
+	if err != nil || extracted == nil {
+		return false, err
+	}
+
+	src, _, err := image.Decode(extracted)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	w, h := stub.Width, stub.Height
+	if scale < 1 {
+		w = max(1, int(float64(w)*scale))
+		h = max(1, int(float64(h)*scale))
+	}
+
+	// Gray images stay gray so the JPEG keeps a single component.
+	var dst draw.Image = image.NewRGBA(image.Rect(0, 0, w, h))
+	colorSpace := model.DeviceRGBCS
+	if stub.Comp == 1 {
+		dst = image.NewGray(image.Rect(0, 0, w, h))
+		colorSpace = model.DeviceGrayCS
+	}
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, src.Bounds(), draw.Src, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: profile.quality}); err != nil {
+		return false, fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	newSD, err := model.CreateDCTImageStreamDict(ctx.XRefTable, buf.Bytes(), w, h, 8, colorSpace)
+	if err != nil {
+		return false, fmt.Errorf("failed to create image: %w", err)
+	}
+	if len(newSD.Raw) >= len(original) {
+		return false, nil
+	}
+
+	entry, ok := ctx.FindTableEntryLight(objNr)
+	if !ok {
+		return false, fmt.Errorf("invalid image object %d", objNr)
+	}
+	entry.Object = *newSD
+
+	return true, nil
+}
@@ -0,0 +1,88 @@
+package merger
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// aesKeyLength selects AES-256, the strongest cipher pdfcpu supports.
+const aesKeyLength = 256
+
+// Permissions are the operations granted to readers who open an encrypted
+// PDF with the user password. The owner password always grants everything.
+type Permissions struct {
+	Print    bool
+	Copy     bool
+	Modify   bool
+	Annotate bool
+}
+
+// Encryption protects a PDF with AES-256. Passwords are secrets and must
+// never be logged.
+type Encryption struct {
+	// UserPassword is required to open the document. Empty lets anyone
+	// open it, subject to Permissions.
+	UserPassword string
+	// OwnerPassword unlocks every permission. When empty a random one is
+	// generated, so the permissions cannot be lifted.
+	OwnerPassword string
+	Permissions   Permissions
+}
+
+// flags converts p into the PDF permission bits.
+func (p Permissions) flags() model.PermissionFlags {
+	flags := model.PermissionsNone
+	if p.Print {
+		flags |= model.PermissionPrintRev2 | model.PermissionPrintRev3
+	}
+	if p.Copy {
+		flags |= model.PermissionExtract | model.PermissionExtractRev3
+	}
+	if p.Modify {
+		flags |= model.PermissionModify | model.PermissionAssembleRev3
+	}
+	if p.Annotate {
+		flags |= model.PermissionModAnnFillForm | model.PermissionFillRev3
+	}
+	return flags
+}
+
+// Encrypt encrypts the PDF read from rs and writes the result to w.
+func Encrypt(rs io.ReadSeeker, w io.Writer, enc Encryption) error {
+	ownerPW := enc.OwnerPassword
+	if ownerPW == "" {
+		ownerPW = rand.Text()
+	}
+
+	conf := model.NewAESConfiguration(enc.UserPassword, ownerPW, aesKeyLength)
+	conf.Permissions = enc.Permissions.flags()
+	conf.ValidationMode = model.ValidationRelaxed
+
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := api.Encrypt(rs, w, conf); err != nil {
+		return fmt.Errorf("failed to encrypt PDF: %w", err)
+	}
+
+	slog.Info("PDF encrypted", "encryption", enc)
+	return nil
+}
+
+// LogValue implements slog.LogValuer, reporting which passwords are set and
+// the permissions without ever revealing the passwords themselves.
+func (e Encryption) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Bool("user_password", e.UserPassword != ""),
+		slog.Bool("owner_password", e.OwnerPassword != ""),
+		slog.Bool("print", e.Permissions.Print),
+		slog.Bool("copy", e.Permissions.Copy),
+		slog.Bool("modify", e.Permissions.Modify),
+		slog.Bool("annotate", e.Permissions.Annotate),
+	)
+}
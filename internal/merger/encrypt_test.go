@@ -0,0 +1,48 @@
+package merger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/psilva1982/rapid_pdf/internal/spool"
+)
+
+func TestEncryptValidates(t *testing.T) {
+	tests := []struct {
+		name string
+		enc  Encryption
+	}{
+		{"user password", Encryption{UserPassword: "user"}},
+		{"owner password", Encryption{OwnerPassword: "owner", Permissions: Permissions{Print: true}}},
+		{"both passwords", Encryption{UserPassword: "user", OwnerPassword: "owner"}},
+	}
+	for _, tt := range tests {
+		for _, metadata := range []bool{false, true} {
+			name := tt.name
+			if metadata {
+				name += " with metadata"
+			}
+			t.Run(name, func(t *testing.T) {
+				out := spool.New(spool.DefaultThreshold)
+				defer out.Close()
+				if err := Encrypt(bytes.NewReader(testPDF(t, 2)), out, tt.enc); err != nil {
+					t.Fatalf("Encrypt: %v", err)
+				}
+				if metadata {
+					if err := SetMetadata(out, Metadata{Title: "Payslip"}, tt.enc.UserPassword); err != nil {
+						t.Fatalf("SetMetadata: %v", err)
+					}
+				}
+
+				if problems := ValidatePDF(out.Reader(), tt.enc.UserPassword); len(problems) > 0 {
+					t.Errorf("ValidatePDF() = %q, want no problems", problems)
+				}
+				if tt.enc.UserPassword != "" {
+					if problems := ValidatePDF(out.Reader(), "wrong"); len(problems) == 0 {
+						t.Error("ValidatePDF() opened the document with a wrong password")
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,520 @@
+package merger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/form"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Form field types.
+const (
+	FieldText     = "text"
+	FieldDate     = "date"
+	FieldCheckBox = "checkbox"
+	FieldRadio    = "radio"
+	FieldComboBox = "combobox"
+	FieldListBox  = "listbox"
+)
+
+// FormField describes a fillable field of a PDF form.
+type FormField struct {
+	// Name is the fully qualified field name, e.g. "applicant.name", which
+	// FillForm values are keyed by.
+	Name string
+	// ID is the object number of the field, accepted by FillForm in place of
+	// the name for fields without one.
+	ID string
+	// Type is one of the Field* constants.
+	Type string
+	// Pages lists the pages the field appears on.
+	Pages []int
+	// Value is the current value. Check boxes hold "true" or "false" and
+	// list boxes their selected options separated by commas.
+	Value string
+	// Options lists the choices of radio buttons, combo and list boxes.
+	Options []string
+	// Multiple tells whether a list box accepts several selected options.
+	Multiple bool
+	// Locked fields are read-only.
+	Locked bool
+}
+
+// FormFields lists the fields of the PDF form read from rs, in page order.
+func FormFields(rs io.ReadSeeker) ([]FormField, error) {
+	group, err := exportForm(rs)
+	if err != nil {
+		return nil, err
+	}
+	if len(group.Forms) == 0 {
+		return nil, nil
+	}
+	f := group.Forms[0]
+
+	var fields []FormField
+	for _, tf := range f.TextFields {
+		fields = append(fields, FormField{Name: tf.Name, ID: tf.ID, Type: FieldText, Pages: tf.Pages, Value: tf.Value, Locked: tf.Locked})
+	}
+	for _, df := range f.DateFields {
+		fields = append(fields, FormField{Name: df.Name, ID: df.ID, Type: FieldDate, Pages: df.Pages, Value: df.Value, Locked: df.Locked})
+	}
+	for _, cb := range f.CheckBoxes {
+		fields = append(fields, FormField{Name: cb.Name, ID: cb.ID, Type: FieldCheckBox, Pages: cb.Pages, Value: strconv.FormatBool(cb.Value), Locked: cb.Locked})
+	}
+	for _, rb := range f.RadioButtonGroups {
+		fields = append(fields, FormField{Name: rb.Name, ID: rb.ID, Type: FieldRadio, Pages: rb.Pages, Value: rb.Value, Options: rb.Options, Locked: rb.Locked})
+	}
+	for _, cb := range f.ComboBoxes {
+		fields = append(fields, FormField{Name: cb.Name, ID: cb.ID, Type: FieldComboBox, Pages: cb.Pages, Value: cb.Value, Options: cb.Options, Locked: cb.Locked})
+	}
+	for _, lb := range f.ListBoxes {
+		fields = append(fields, FormField{Name: lb.Name, ID: lb.ID, Type: FieldListBox, Pages: lb.Pages, Value: strings.Join(lb.Values, ","), Options: lb.Options, Multiple: lb.Multi, Locked: lb.Locked})
+	}
+
+	slices.SortStableFunc(fields, func(a, b FormField) int {
+		return firstPage(a.Pages) - firstPage(b.Pages)
+	})
+	return fields, nil
+}
+
+// firstPage returns the lowest of pages, or 0 when there are none.
+func firstPage(pages []int) int {
+	if len(pages) == 0 {
+		return 0
+	}
+	return slices.Min(pages)
+}
+
+// FillForm fills the fields of the PDF form read from rs and writes the
+// result to w. values maps field names, or IDs, to their new value: a string
+// for text and date fields, radio buttons and combo boxes, a boolean for
+// check boxes and a string or a list of strings for list boxes. Fields left
+// out keep their value.
+//
+// Flatten draws every field into the page content and removes the form, so
+// the document can no longer be edited and prints the same in every viewer.
+func FillForm(rs io.ReadSeeker, w io.Writer, values map[string]any, flatten bool) error {
+	if len(values) == 0 && !flatten {
+		return fmt.Errorf("no field values given")
+	}
+
+	fields, err := FormFields(rs)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		return fmt.Errorf("PDF has no form fields")
+	}
+
+	f, err := formData(fields, values)
+	if err != nil {
+		return err
+	}
+
+	var filled bytes.Buffer
+	if len(values) == 0 {
+		if err := passThrough(rs, &filled); err != nil {
+			return err
+		}
+	} else {
+		data, err := json.Marshal(form.FormGroup{Forms: []form.Form{f}})
+		if err != nil {
+			return err
+		}
+		if _, err := rs.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		conf := model.NewDefaultConfiguration()
+		conf.ValidationMode = model.ValidationRelaxed
+		if err := api.FillForm(rs, bytes.NewReader(data), &filled, conf); err != nil {
+			return fmt.Errorf("failed to fill form: %w", err)
+		}
+	}
+
+	if flatten {
+		if err := flattenFilled(filled.Bytes(), w); err != nil {
+			return err
+		}
+	} else if _, err := filled.WriteTo(w); err != nil {
+		return err
+	}
+
+	slog.Info("form filled", "fields", len(values), "flattened", flatten)
+	return nil
+}
+
+// flattenFilled flattens the filled form in data and writes the result to w.
+func flattenFilled(data []byte, w io.Writer) error {
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+	ctx, err := readPDF(bytes.NewReader(data), conf)
+	if err != nil {
+		return fmt.Errorf("failed to read filled form: %w", err)
+	}
+	if err := flattenForm(ctx); err != nil {
+		return fmt.Errorf("failed to flatten form: %w", err)
+	}
+	if err := api.WriteContext(ctx, w); err != nil {
+		return fmt.Errorf("failed to write flattened form: %w", err)
+	}
+	return nil
+}
+
+// formData converts values into the pdfcpu form description of the fields
+// to fill, checking every value against the field it is meant for.
+func formData(fields []FormField, values map[string]any) (form.Form, error) {
+	byKey := make(map[string]FormField, 2*len(fields))
+	for _, field := range fields {
+		byKey[field.ID] = field
+	}
+	for _, field := range fields {
+		if field.Name != "" {
+			byKey[field.Name] = field
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	var f form.Form
+	for _, key := range keys {
+		field, ok := byKey[key]
+		if !ok {
+			return f, fmt.Errorf("unknown form field %q", key)
+		}
+		value := values[key]
+
+		switch field.Type {
+		case FieldCheckBox:
+			checked, err := boolValue(value)
+			if err != nil {
+				return f, fmt.Errorf("field %q: %w", key, err)
+			}
+			f.CheckBoxes = append(f.CheckBoxes, &form.CheckBox{ID: field.ID, Name: field.Name, Value: checked})
+
+		case FieldListBox:
+			selected, err := listValue(value)
+			if err != nil {
+				return f, fmt.Errorf("field %q: %w", key, err)
+			}
+			if len(selected) > 1 && !field.Multiple {
+				return f, fmt.Errorf("field %q accepts a single option", key)
+			}
+			for _, s := range selected {
+				if !slices.Contains(field.Options, s) {
+					return f, fmt.Errorf("field %q has no option %q", key, s)
+				}
+			}
+			f.ListBoxes = append(f.ListBoxes, &form.ListBox{ID: field.ID, Name: field.Name, Values: selected})
+
+		default:
+			s, err := stringValue(value)
+			if err != nil {
+				return f, fmt.Errorf("field %q: %w", key, err)
+			}
+			switch field.Type {
+			case FieldText:
+				f.TextFields = append(f.TextFields, &form.TextField{ID: field.ID, Name: field.Name, Value: s})
+			case FieldDate:
+				f.DateFields = append(f.DateFields, &form.DateField{ID: field.ID, Name: field.Name, Value: s})
+			case FieldRadio:
+				if s != "" && !slices.Contains(field.Options, s) {
+					return f, fmt.Errorf("field %q has no option %q", key, s)
+				}
+				f.RadioButtonGroups = append(f.RadioButtonGroups, &form.RadioButtonGroup{ID: field.ID, Name: field.Name, Value: s})
+			case FieldComboBox:
+				f.ComboBoxes = append(f.ComboBoxes, &form.ComboBox{ID: field.ID, Name: field.Name, Value: s})
+			}
+		}
+	}
+	return f, nil
+}
+
+// stringValue converts a JSON value to the text of a field. Numbers and
+// booleans are accepted as a convenience.
+func stringValue(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return "", fmt.Errorf("expected a string, got %T", v)
+	}
+}
+
+// boolValue converts a JSON value to the state of a check box.
+func boolValue(v any) (bool, error) {
+	switch v := v.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(v) {
+		case "true", "yes", "on", "1", "x":
+			return true, nil
+		case "false", "no", "off", "0", "":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("expected a boolean, got %v", v)
+}
+
+// listValue converts a JSON value to the selected options of a list box.
+func listValue(v any) ([]string, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []string:
+		return v, nil
+	case []any:
+		selected := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a list of strings, got %T", item)
+			}
+			selected = append(selected, s)
+		}
+		return selected, nil
+	default:
+		return nil, fmt.Errorf("expected a string or a list of strings, got %T", v)
+	}
+}
+
+// exportForm reads the form of the PDF read from rs. A PDF without a form
+// yields an empty group.
+func exportForm(rs io.ReadSeeker) (*form.FormGroup, error) {
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.EXPORTFORMFIELDS
+	conf.ValidationMode = model.ValidationRelaxed
+
+	ctx, err := readPDF(rs, conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	if ctx.Form == nil {
+		return &form.FormGroup{}, nil
+	}
+
+	group, _, err := form.ExportForm(ctx.XRefTable, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read form: %w", err)
+	}
+	return group, nil
+}
+
+// flattenForm draws the appearance of every form field of ctx into the
+// content of its page and removes the fields and the form.
+func flattenForm(ctx *model.Context) error {
+	for p := 1; p <= ctx.PageCount; p++ {
+		if err := flattenPage(ctx, p); err != nil {
+			return fmt.Errorf("page %d: %w", p, err)
+		}
+	}
+
+	root, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+	root.Delete("AcroForm")
+	return nil
+}
+
+// flattenPage replaces the widget annotations of a page with their normal
+// appearance, drawn as form XObjects on top of the page content.
+func flattenPage(ctx *model.Context, page int) error {
+	d, _, inh, err := ctx.PageDict(page, false)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return fmt.Errorf("page not found")
+	}
+
+	annots, err := ctx.DereferenceArray(d["Annots"])
+	if err != nil || annots == nil {
+		return nil
+	}
+
+	var (
+		kept     types.Array
+		content  strings.Builder
+		xobjects = types.Dict{}
+		widgets  int
+	)
+	for _, obj := range annots {
+		annot, err := ctx.DereferenceDict(obj)
+		if err != nil || annot == nil || annot.Subtype() == nil || *annot.Subtype() != "Widget" {
+			kept = append(kept, obj)
+			continue
+		}
+		widgets++
+
+		if flags := annot.IntEntry("F"); flags != nil && *flags&int(model.AnnHidden|model.AnnInvisible) != 0 {
+			continue
+		}
+		ap, cm, err := appearance(ctx, annot)
+		if err != nil {
+			return err
+		}
+		if ap == nil {
+			continue
+		}
+
+		name := fmt.Sprintf("RapidField%d", len(xobjects))
+		xobjects[name] = *ap
+		fmt.Fprintf(&content, "q %s /%s Do Q\n", cm, name)
+	}
+	if widgets == 0 {
+		return nil
+	}
+
+	if len(kept) > 0 {
+		d["Annots"] = kept
+	} else {
+		d.Delete("Annots")
+	}
+	if len(xobjects) == 0 {
+		return nil
+	}
+
+	// The page may inherit its resources from the page tree, so they are
+	// copied onto the page before the appearances are added.
+	res := types.Dict{}
+	if inh != nil && inh.Resources != nil {
+		res = inh.Resources.Clone().(types.Dict)
+	}
+	xo, err := ctx.DereferenceDict(res["XObject"])
+	if err != nil {
+		return err
+	}
+	if xo == nil {
+		xo = types.Dict{}
+	} else {
+		xo = xo.Clone().(types.Dict)
+	}
+	for name, ref := range xobjects {
+		xo[name] = ref
+	}
+	res["XObject"] = xo
+	d["Resources"] = res
+
+	// Wrap the existing content so state it leaves behind cannot affect the
+	// appearances.
+	if err := ctx.AppendContent(d, []byte("\nQ\n"+content.String())); err != nil {
+		return err
+	}
+	return prependContent(ctx, d, []byte("q\n"))
+}
+
+// appearance returns the normal appearance stream of a widget annotation,
+// for the state it is in, along with the matrix that draws it onto the
+// annotation rectangle. It returns a nil stream for widgets without one.
+func appearance(ctx *model.Context, annot types.Dict) (*types.IndirectRef, affine, error) {
+	ap, err := ctx.DereferenceDict(annot["AP"])
+	if err != nil || ap == nil {
+		return nil, affine{}, err
+	}
+
+	n := ap["N"]
+	if states, err := ctx.DereferenceDict(n); err == nil && states != nil {
+		// Check boxes and radio buttons have an appearance per state,
+		// selected by the AS entry.
+		as := annot.NameEntry("AS")
+		if as == nil {
+			return nil, affine{}, nil
+		}
+		n = states[*as]
+	}
+	ref, ok := n.(types.IndirectRef)
+	if !ok {
+		return nil, affine{}, nil
+	}
+	sd, _, err := ctx.DereferenceStreamDict(ref)
+	if err != nil || sd == nil {
+		return nil, affine{}, err
+	}
+
+	rect, err := ctx.RectForArray(annot.ArrayEntry("Rect"))
+	if err != nil || rect == nil {
+		return nil, affine{}, nil
+	}
+	bbox, err := ctx.RectForArray(sd.ArrayEntry("BBox"))
+	if err != nil || bbox == nil {
+		return nil, affine{}, nil
+	}
+
+	m := translate(0, 0)
+	if arr := sd.ArrayEntry("Matrix"); len(arr) == 6 {
+		var v [6]float64
+		for i, obj := range arr {
+			if v[i], err = ctx.DereferenceNumber(obj); err != nil {
+				return nil, affine{}, err
+			}
+		}
+		m = affine{v[0], v[1], v[2], v[3], v[4], v[5]}
+	}
+
+	// The bounding box, transformed by the stream's matrix, is mapped onto
+	// the annotation rectangle (PDF 32000-1, 12.5.5).
+	x1, y1 := m.apply(bbox.LL.X, bbox.LL.Y)
+	x2, y2 := m.apply(bbox.UR.X, bbox.UR.Y)
+	x3, y3 := m.apply(bbox.LL.X, bbox.UR.Y)
+	x4, y4 := m.apply(bbox.UR.X, bbox.LL.Y)
+	llx, lly := min(x1, x2, x3, x4), min(y1, y2, y3, y4)
+	urx, ury := max(x1, x2, x3, x4), max(y1, y2, y3, y4)
+	if urx == llx || ury == lly {
+		return nil, affine{}, nil
+	}
+
+	sx, sy := rect.Width()/(urx-llx), rect.Height()/(ury-lly)
+	cm := affine{a: sx, d: sy, e: rect.LL.X - llx*sx, f: rect.LL.Y - lly*sy}
+	return &ref, cm, nil
+}
+
+// prependContent puts bb in front of the content of a page.
+func prependContent(ctx *model.Context, page types.Dict, bb []byte) error {
+	sd, err := ctx.NewStreamDictForBuf(bb)
+	if err != nil {
+		return err
+	}
+	if err := sd.Encode(); err != nil {
+		return err
+	}
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		return err
+	}
+
+	contents, found := page.Find("Contents")
+	if !found {
+		page["Contents"] = *ir
+		return nil
+	}
+	if arr, err := ctx.DereferenceArray(contents); err == nil && arr != nil {
+		page["Contents"] = append(types.Array{*ir}, arr...)
+		return nil
+	}
+	page["Contents"] = types.Array{*ir, contents}
+	return nil
+}
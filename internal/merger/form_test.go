@@ -0,0 +1,114 @@
+package merger
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+var testFormFields = []FormField{
+	{Name: "name", ID: "10", Type: FieldText},
+	{Name: "birth", ID: "11", Type: FieldDate},
+	{Name: "agree", ID: "12", Type: FieldCheckBox},
+	{Name: "plan", ID: "13", Type: FieldRadio, Options: []string{"basic", "pro"}},
+	{Name: "state", ID: "14", Type: FieldComboBox, Options: []string{"SP", "RJ"}},
+	{Name: "topics", ID: "15", Type: FieldListBox, Options: []string{"go", "pdf", "web"}, Multiple: true},
+	{Name: "color", ID: "16", Type: FieldListBox, Options: []string{"red", "blue"}},
+	{ID: "17", Type: FieldText},
+}
+
+func TestFormData(t *testing.T) {
+	f, err := formData(testFormFields, map[string]any{
+		"name":   "Ana",
+		"birth":  "2000-01-31",
+		"agree":  "yes",
+		"plan":   "pro",
+		"state":  float64(35),
+		"topics": []any{"go", "pdf"},
+		"color":  "blue",
+		"17":     true,
+	})
+	if err != nil {
+		t.Fatalf("formData: %v", err)
+	}
+
+	var texts []string
+	for _, tf := range f.TextFields {
+		texts = append(texts, tf.ID+"="+tf.Value)
+	}
+	slices.Sort(texts)
+	if want := []string{"10=Ana", "17=true"}; !slices.Equal(texts, want) {
+		t.Errorf("text fields = %q, want %q", texts, want)
+	}
+	if len(f.DateFields) != 1 || f.DateFields[0].Value != "2000-01-31" {
+		t.Errorf("date fields = %+v", f.DateFields)
+	}
+	if len(f.CheckBoxes) != 1 || !f.CheckBoxes[0].Value {
+		t.Errorf("check boxes = %+v, want agree checked", f.CheckBoxes)
+	}
+	if len(f.RadioButtonGroups) != 1 || f.RadioButtonGroups[0].Value != "pro" {
+		t.Errorf("radio buttons = %+v", f.RadioButtonGroups)
+	}
+	if len(f.ComboBoxes) != 1 || f.ComboBoxes[0].Value != "35" {
+		t.Errorf("combo boxes = %+v, want the number as text", f.ComboBoxes)
+	}
+	if len(f.ListBoxes) != 2 {
+		t.Fatalf("list boxes = %+v", f.ListBoxes)
+	}
+	for _, lb := range f.ListBoxes {
+		want := map[string][]string{"topics": {"go", "pdf"}, "color": {"blue"}}[lb.Name]
+		if !slices.Equal(lb.Values, want) {
+			t.Errorf("list box %s = %q, want %q", lb.Name, lb.Values, want)
+		}
+	}
+}
+
+func TestFormDataErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]any
+		want   string
+	}{
+		{"unknown field", map[string]any{"nickname": "Ana"}, "unknown form field"},
+		{"object as text", map[string]any{"name": map[string]any{"first": "Ana"}}, "expected a string"},
+		{"invalid check box", map[string]any{"agree": "maybe"}, "expected a boolean"},
+		{"unknown radio option", map[string]any{"plan": "gold"}, "no option"},
+		{"unknown list option", map[string]any{"topics": []any{"go", "rust"}}, "no option"},
+		{"several options", map[string]any{"color": []any{"red", "blue"}}, "single option"},
+		{"list of numbers", map[string]any{"topics": []any{1.0}}, "list of strings"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := formData(testFormFields, tt.values)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("formData() error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoolValue(t *testing.T) {
+	tests := []struct {
+		value any
+		want  bool
+	}{
+		{true, true},
+		{false, false},
+		{nil, false},
+		{"Yes", true},
+		{"on", true},
+		{"1", true},
+		{"x", true},
+		{"off", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		got, err := boolValue(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("boolValue(%#v) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+	if _, err := boolValue(1.0); err == nil {
+		t.Error("boolValue(1.0) succeeded")
+	}
+}
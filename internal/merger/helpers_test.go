@@ -0,0 +1,102 @@
+package merger
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// testPDF returns an uncompressed A4 document whose pages show "Page 1",
+// "Page 2" and so on.
+func testPDF(t *testing.T, pages int) []byte {
+	t.Helper()
+
+	var b bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	kids := make([]string, pages)
+	for i := range kids {
+		kids[i] = fmt.Sprintf("%d 0 R", 3+2*i)
+	}
+
+	b.WriteString("%PDF-1.7\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), pages))
+	for i := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Contents %d 0 R "+
+			"/Resources << /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >> >> >>", 4+2*i))
+		content := fmt.Sprintf("BT /F1 24 Tf 72 720 Td (Page %d) Tj ET", i+1)
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return b.Bytes()
+}
+
+// writeTestPDF writes data to a file in a temporary directory and returns its
+// path.
+func writeTestPDF(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// readTestPDF reads the PDF in data into a pdfcpu context.
+func readTestPDF(t *testing.T, data []byte) *model.Context {
+	t.Helper()
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+	ctx, err := readPDF(bytes.NewReader(data), conf)
+	if err != nil {
+		t.Fatalf("failed to read PDF: %v", err)
+	}
+	return ctx
+}
+
+// pageLabels returns the "Page n" label drawn by testPDF on every page of
+// the PDF at path.
+func pageLabels(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := readTestPDF(t, data)
+	labels := make([]string, 0, ctx.PageCount)
+	for page := 1; page <= ctx.PageCount; page++ {
+		d, _, _, err := ctx.PageDict(page, false)
+		if err != nil {
+			t.Fatalf("page %d: %v", page, err)
+		}
+		content, err := ctx.PageContent(d, page)
+		if err != nil {
+			t.Fatalf("page %d: %v", page, err)
+		}
+		m := testLabelPattern.FindSubmatch(content)
+		if m == nil {
+			t.Fatalf("page %d has no label", page)
+		}
+		labels = append(labels, string(m[1]))
+	}
+	return labels
+}
+
+var testLabelPattern = regexp.MustCompile(`\((Page \d+)\) Tj`)
@@ -0,0 +1,10 @@
+package merger
+
+import _ "embed"
+
+// srgbProfile is the standard sRGB IEC61966-2.1 ICC profile, used as the
+// output intent of PDF/A documents. Chrome prints in sRGB, so the profile
+// matches every page it renders.
+//
+//go:embed icc/sRGB_IEC61966-2.1.icc
+var srgbProfile []byte
@@ -0,0 +1,34 @@
+package merger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestSRGBProfile(t *testing.T) {
+	p := srgbProfile
+	if len(p) < 132 {
+		t.Fatalf("profile is only %d bytes long", len(p))
+	}
+	if size := binary.BigEndian.Uint32(p); int(size) != len(p) {
+		t.Errorf("header size = %d, want %d", size, len(p))
+	}
+	for _, f := range []struct {
+		name   string
+		offset int
+		want   string
+	}{
+		{"signature", 36, "acsp"},
+		{"device class", 12, "mntr"},
+		{"color space", 16, "RGB "},
+		{"connection space", 20, "XYZ "},
+	} {
+		if got := string(p[f.offset : f.offset+4]); got != f.want {
+			t.Errorf("%s = %q, want %q", f.name, got, f.want)
+		}
+	}
+	if !bytes.Contains(p, []byte(srgbOutputCondition)) {
+		t.Errorf("profile does not describe itself as %q", srgbOutputCondition)
+	}
+}
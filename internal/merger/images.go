@@ -0,0 +1,33 @@
+package merger
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// imageImportConfig lays each image out centered on an A4 page, scaled to fit
+// with a small border, matching the paper size Chrome prints with.
+const imageImportConfig = "form:A4, pos:c, sc:0.95"
+
+// ImagesToPDF converts PNG or JPEG images into a PDF with one page per image.
+// It uses pdfcpu's image import, so no browser is involved.
+func ImagesToPDF(imageFiles []string, outputFile string) error {
+	if len(imageFiles) == 0 {
+		return fmt.Errorf("no images to import")
+	}
+
+	imp, err := api.Import(imageImportConfig, types.POINTS)
+	if err != nil {
+		return fmt.Errorf("invalid image import config: %w", err)
+	}
+
+	if err := api.ImportImagesFile(imageFiles, outputFile, imp, nil); err != nil {
+		return fmt.Errorf("failed to import images: %w", err)
+	}
+
+	slog.Info("images converted to PDF", "image_count", len(imageFiles), "output", outputFile)
+	return nil
+}
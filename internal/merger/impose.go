@@ -0,0 +1,141 @@
+package merger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Imposition modes.
+const (
+	Imposition2Up  = "2-up"
+	Imposition4Up  = "4-up"
+	Imposition9Up  = "9-up"
+	ImpositionBook = "booklet"
+)
+
+// ImpositionModes lists the accepted Imposition modes.
+var ImpositionModes = []string{Imposition2Up, Imposition4Up, Imposition9Up, ImpositionBook}
+
+// pagesPerSheet is the number of document pages printed on each side of a
+// sheet for every mode.
+var pagesPerSheet = map[string]int{
+	Imposition2Up:  2,
+	Imposition4Up:  4,
+	Imposition9Up:  9,
+	ImpositionBook: 2,
+}
+
+// Imposition lays several pages of a document out on each sheet, for
+// handouts, or reorders them into a saddle-stitched booklet that reads in
+// order once its sheets are printed double-sided, folded and stapled.
+type Imposition struct {
+	// Mode is one of ImpositionModes.
+	Mode string
+	// PaperSize is the sheet size, such as "A4" or "Letter". Defaults to
+	// DefaultPaperSize.
+	PaperSize string
+	// Landscape turns the sheet sideways, which suits 2-up handouts and
+	// booklets of portrait pages.
+	Landscape bool
+	// Border draws a frame around every page on the sheet.
+	Border bool
+	// Margin is the space around every page on the sheet, in points.
+	Margin float64
+}
+
+// Validate checks that the mode and paper size are known.
+func (i Imposition) Validate() error {
+	if !slices.Contains(ImpositionModes, i.Mode) {
+		return fmt.Errorf("invalid imposition mode %q", i.Mode)
+	}
+	if i.Margin < 0 {
+		return fmt.Errorf("margin must not be negative")
+	}
+	_, err := i.nup()
+	return err
+}
+
+// nup converts the imposition into a pdfcpu N-up configuration.
+func (i Imposition) nup() (*model.NUp, error) {
+	w, h, err := PageLayout{PaperSize: i.PaperSize, Landscape: i.Landscape}.paper()
+	if err != nil {
+		return nil, err
+	}
+
+	var nup *model.NUp
+	if i.Mode == ImpositionBook {
+		nup = pdfcpu.DefaultBookletConfig()
+	} else {
+		nup = model.DefaultNUpConfig()
+	}
+	nup.PageDim = &types.Dim{Width: w, Height: h}
+	nup.PageSize = PageLayout{PaperSize: i.PaperSize}.paperSize()
+	nup.UserDim = true
+	nup.Border = i.Border
+	nup.Margin = i.Margin
+
+	if err := pdfcpu.ParseNUpValue(pagesPerSheet[i.Mode], nup); err != nil {
+		return nil, err
+	}
+	return nup, nil
+}
+
+// Impose lays out the PDF read from rs as requested and writes the result
+// to w. Bookmarks and links point at pages that no longer exist afterwards,
+// so they are dropped.
+func Impose(rs io.ReadSeeker, w io.Writer, imp Imposition) error {
+	if err := imp.Validate(); err != nil {
+		return err
+	}
+	nup, err := imp.nup()
+	if err != nil {
+		return err
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+	if imp.Mode == ImpositionBook {
+		conf.Cmd = model.BOOKLET
+	} else {
+		conf.Cmd = model.NUP
+	}
+
+	ctx, err := readPDF(rs, conf)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+	pages := ctx.PageCount
+
+	all := make(types.IntSet, pages)
+	for p := 1; p <= pages; p++ {
+		all[p] = true
+	}
+
+	if imp.Mode == ImpositionBook {
+		err = pdfcpu.BookletFromPDF(ctx, all, nup)
+	} else {
+		err = pdfcpu.NUpFromPDF(ctx, all, nup)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to impose pages: %w", err)
+	}
+
+	if root, err := ctx.Catalog(); err == nil {
+		root.Delete("Outlines")
+	}
+
+	if err := api.WriteContext(ctx, w); err != nil {
+		return fmt.Errorf("failed to write imposed PDF: %w", err)
+	}
+
+	// pdfcpu counts the new sheets on top of the original pages.
+	slog.Info("pages imposed", "mode", imp.Mode, "pages", pages, "sheets", ctx.PageCount-pages)
+	return nil
+}
@@ -0,0 +1,35 @@
+package merger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestImpose(t *testing.T) {
+	tests := []struct {
+		mode  string
+		pages int
+		want  int
+	}{
+		{Imposition2Up, 5, 3},
+		{Imposition4Up, 5, 2},
+		{Imposition9Up, 5, 1},
+		{ImpositionBook, 4, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			var out bytes.Buffer
+			err := Impose(bytes.NewReader(testPDF(t, tt.pages)), &out, Imposition{Mode: tt.mode, Landscape: true})
+			if err != nil {
+				t.Fatalf("Impose: %v", err)
+			}
+			if got := readTestPDF(t, out.Bytes()).PageCount; got != tt.want {
+				t.Errorf("%d pages imposed %s gave %d sheets, want %d", tt.pages, tt.mode, got, tt.want)
+			}
+		})
+	}
+
+	if err := Impose(bytes.NewReader(testPDF(t, 1)), &bytes.Buffer{}, Imposition{Mode: "5-up"}); err == nil {
+		t.Error("unknown mode succeeded")
+	}
+}
@@ -0,0 +1,242 @@
+package merger
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Inspection describes a PDF without rendering it.
+type Inspection struct {
+	// Version is the PDF version, e.g. "1.7".
+	Version string
+	Pages   int
+	// PageSizes lists every distinct page size along with the pages that
+	// have it.
+	PageSizes []PageSize
+	Metadata  Metadata
+	// CreationDate and ModDate are the raw info dictionary dates, e.g.
+	// "D:20240131120000+00'00'".
+	CreationDate string
+	ModDate      string
+	// Tagged tells whether the document carries a structure tree for
+	// accessibility.
+	Tagged    bool
+	Encrypted bool
+	// Permissions lists what an encrypted document allows: "print",
+	// "print_high_quality", "copy", "modify", "annotate", "fill_forms" and
+	// "assemble". It is nil for documents that are not encrypted.
+	Permissions []string
+	Signed      bool
+	// Form tells whether the document has fillable fields.
+	Form        bool
+	Fonts       []FontInfo
+	Attachments []AttachmentInfo
+	Bookmarks   []BookmarkInfo
+	// Valid is false when the document breaks the PDF specification, as
+	// described by ValidationErrors. Such documents often still open fine.
+	Valid            bool
+	ValidationErrors []string
+}
+
+// PageSize is a page size found in a PDF, in points, as displayed.
+type PageSize struct {
+	Width  float64
+	Height float64
+	// Paper is the matching paper name, such as "A4", if any.
+	Paper string
+	// Pages lists the pages of this size, e.g. "1-3,7".
+	Pages string
+}
+
+// FontInfo describes a font used by a PDF.
+type FontInfo struct {
+	Name string
+	// Type is the font type, e.g. "TrueType" or "Type0".
+	Type     string
+	Embedded bool
+	// Subset tells whether only the glyphs in use are embedded.
+	Subset bool
+}
+
+// AttachmentInfo describes a file embedded into a PDF.
+type AttachmentInfo struct {
+	Name        string
+	Description string
+}
+
+// BookmarkInfo is an entry of the outline of a PDF.
+type BookmarkInfo struct {
+	Title    string
+	Page     int
+	Children []BookmarkInfo
+}
+
+// Inspect reads the PDF from rs and describes it. password opens encrypted
+// documents and may be empty otherwise.
+func Inspect(rs io.ReadSeeker, password string) (*Inspection, error) {
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.LISTINFO
+	conf.ValidationMode = model.ValidationRelaxed
+	conf.UserPW, conf.OwnerPW = password, password
+
+	ctx, err := readPDF(rs, conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	// Fonts are collected while optimizing.
+	if err := api.OptimizeContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to read fonts: %w", err)
+	}
+
+	info, err := pdfcpu.Info(ctx, "", nil, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document info: %w", err)
+	}
+
+	in := &Inspection{
+		Version: info.Version,
+		Pages:   info.PageCount,
+		Metadata: Metadata{
+			Title:      info.Title,
+			Author:     info.Author,
+			Subject:    info.Subject,
+			Keywords:   info.Keywords,
+			Creator:    info.Creator,
+			Producer:   info.Producer,
+			Properties: info.Properties,
+		},
+		CreationDate: info.CreationDate,
+		ModDate:      info.ModificationDate,
+		Tagged:       info.Tagged,
+		Encrypted:    info.Encrypted,
+		Signed:       info.Signatures,
+		Form:         info.Form,
+	}
+	if root, err := ctx.Catalog(); err == nil {
+		if lang, err := ctx.DereferenceStringOrHexLiteral(root["Lang"], model.V10, nil); err == nil {
+			in.Metadata.Language = lang
+		}
+	}
+	if info.Encrypted {
+		in.Permissions = permissionNames(model.PermissionFlags(info.Permissions))
+	}
+
+	if in.PageSizes, err = pageSizes(ctx); err != nil {
+		return nil, fmt.Errorf("failed to read page sizes: %w", err)
+	}
+
+	for _, f := range info.Fonts {
+		in.Fonts = append(in.Fonts, FontInfo{Name: f.Name, Type: f.Type, Embedded: f.Embedded, Subset: f.Prefix != ""})
+	}
+	slices.SortFunc(in.Fonts, func(a, b FontInfo) int { return strings.Compare(a.Name, b.Name) })
+	in.Fonts = slices.Compact(in.Fonts)
+
+	for _, a := range info.Attachments {
+		in.Attachments = append(in.Attachments, AttachmentInfo{Name: a.FileName, Description: a.Desc})
+	}
+
+	bookmarks, err := pdfcpu.Bookmarks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bookmarks: %w", err)
+	}
+	in.Bookmarks = bookmarkInfos(bookmarks)
+
+	in.ValidationErrors = ValidatePDF(rs, password)
+	in.Valid = len(in.ValidationErrors) == 0
+
+	return in, nil
+}
+
+// permissionNames lists the permissions set in p.
+func permissionNames(p model.PermissionFlags) []string {
+	names := []string{}
+	for _, perm := range []struct {
+		flag model.PermissionFlags
+		name string
+	}{
+		{model.PermissionPrintRev2, "print"},
+		{model.PermissionPrintRev3, "print_high_quality"},
+		{model.PermissionExtract, "copy"},
+		{model.PermissionModify, "modify"},
+		{model.PermissionModAnnFillForm, "annotate"},
+		{model.PermissionFillRev3, "fill_forms"},
+		{model.PermissionAssembleRev3, "assemble"},
+	} {
+		if p&perm.flag != 0 {
+			names = append(names, perm.name)
+		}
+	}
+	return names
+}
+
+// pageSizes groups the pages of ctx by size.
+func pageSizes(ctx *model.Context) ([]PageSize, error) {
+	dims, err := ctx.PageDims()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		sizes []PageSize
+		spans [][][2]int
+	)
+	for i, d := range dims {
+		page := i + 1
+		w, h := math.Round(d.Width*100)/100, math.Round(d.Height*100)/100
+
+		j := slices.IndexFunc(sizes, func(s PageSize) bool { return s.Width == w && s.Height == h })
+		if j < 0 {
+			sizes = append(sizes, PageSize{Width: w, Height: h, Paper: paperName(w, h)})
+			spans = append(spans, nil)
+			j = len(sizes) - 1
+		}
+
+		if n := len(spans[j]); n > 0 && spans[j][n-1][1] == page-1 {
+			spans[j][n-1][1] = page
+		} else {
+			spans[j] = append(spans[j], [2]int{page, page})
+		}
+	}
+
+	for i := range sizes {
+		labels := make([]string, len(spans[i]))
+		for k, span := range spans[i] {
+			labels[k] = spanLabel(span[0], span[1])
+		}
+		sizes[i].Pages = strings.Join(labels, ",")
+	}
+	return sizes, nil
+}
+
+// paperName returns the name of the standard paper of the given size, in
+// either orientation, or "" if there is none. Sizes within a point match.
+func paperName(w, h float64) string {
+	if w > h {
+		w, h = h, w
+	}
+	for _, name := range PaperSizes() {
+		d := types.PaperSize[name]
+		pw, ph := min(d.Width, d.Height), max(d.Width, d.Height)
+		if math.Abs(pw-w) <= 1 && math.Abs(ph-h) <= 1 {
+			return name
+		}
+	}
+	return ""
+}
+
+// bookmarkInfos converts a pdfcpu outline.
+func bookmarkInfos(bookmarks []pdfcpu.Bookmark) []BookmarkInfo {
+	var infos []BookmarkInfo
+	for _, b := range bookmarks {
+		infos = append(infos, BookmarkInfo{Title: b.Title, Page: b.PageFrom, Children: bookmarkInfos(b.Kids)})
+	}
+	return infos
+}
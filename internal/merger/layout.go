@@ -0,0 +1,304 @@
+package merger
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Page scaling modes.
+const (
+	// ScaleFit shrinks or enlarges a page until it fits the paper, leaving
+	// blank bands along two edges when the aspect ratios differ.
+	ScaleFit = "fit"
+	// ScaleFill scales a page until it covers the paper, cropping what
+	// sticks out along two edges.
+	ScaleFill = "fill"
+)
+
+// ScaleModes lists the accepted PageLayout scaling modes.
+var ScaleModes = []string{ScaleFit, ScaleFill}
+
+// DefaultPaperSize is the paper size of a PageLayout without one, matching
+// the paper Chrome prints with.
+const DefaultPaperSize = "A4"
+
+// PageLayout gives every page of a merged document the same dimensions, so
+// Chrome-printed pages, uploaded PDFs and images print alike.
+type PageLayout struct {
+	// PaperSize is a paper name such as "A4", "A3" or "Letter". Defaults to
+	// DefaultPaperSize.
+	PaperSize string
+	// Landscape turns the paper sideways.
+	Landscape bool
+	// Scale is one of ScaleModes. Defaults to ScaleFit.
+	Scale string
+	// AutoRotate turns pages whose orientation differs from the paper's by
+	// 90° counterclockwise, so landscape pages use the whole of a portrait
+	// paper instead of being shrunk onto it.
+	AutoRotate bool
+	// Margin is the blank border kept on every edge of the paper, in points.
+	Margin float64
+}
+
+// Validate checks that the paper size and scaling mode are known and that
+// the margins leave room for content.
+func (l PageLayout) Validate() error {
+	if l.Scale != "" && !slices.Contains(ScaleModes, l.Scale) {
+		return fmt.Errorf("invalid scale mode %q", l.Scale)
+	}
+	if l.Margin < 0 {
+		return fmt.Errorf("margin must not be negative")
+	}
+
+	w, h, err := l.paper()
+	if err != nil {
+		return err
+	}
+	if 2*l.Margin >= min(w, h) {
+		return fmt.Errorf("margin of %g points leaves no room on %s paper", l.Margin, l.paperSize())
+	}
+	return nil
+}
+
+// PaperSizes lists the accepted paper names.
+func PaperSizes() []string {
+	names := make([]string, 0, len(types.PaperSize))
+	for name := range types.PaperSize {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// paperSize returns the paper name, applying the default.
+func (l PageLayout) paperSize() string {
+	if l.PaperSize == "" {
+		return DefaultPaperSize
+	}
+	return l.PaperSize
+}
+
+// paper returns the paper width and height in points.
+func (l PageLayout) paper() (float64, float64, error) {
+	name := l.paperSize()
+	var dim *types.Dim
+	for key, d := range types.PaperSize {
+		if strings.EqualFold(key, name) {
+			dim = d
+			break
+		}
+	}
+	if dim == nil {
+		return 0, 0, fmt.Errorf("unknown paper size %q", name)
+	}
+
+	w, h := dim.Width, dim.Height
+	if l.Landscape != (w > h) {
+		w, h = h, w
+	}
+	return w, h, nil
+}
+
+// applyLayout redraws every page of ctx onto the paper of layout. Page
+// content is scaled, rotated and centered inside the margins, and the
+// rectangles of annotations such as links follow it.
+func applyLayout(ctx *model.Context, layout PageLayout) error {
+	paperW, paperH, err := layout.paper()
+	if err != nil {
+		return err
+	}
+	box := types.NewRectangle(layout.Margin, layout.Margin, paperW-layout.Margin, paperH-layout.Margin)
+
+	for p := 1; p <= ctx.PageCount; p++ {
+		if err := layoutPage(ctx, p, layout, box, paperW, paperH); err != nil {
+			return fmt.Errorf("failed to lay out page %d: %w", p, err)
+		}
+	}
+
+	ctx.EnsureVersionForWriting()
+	return nil
+}
+
+// layoutPage redraws a single page inside box on paper of the given size.
+func layoutPage(ctx *model.Context, page int, layout PageLayout, box *types.Rectangle, paperW, paperH float64) error {
+	d, _, inh, err := ctx.PageDict(page, false)
+	if err != nil {
+		return err
+	}
+	if d == nil || inh == nil || inh.MediaBox == nil {
+		return fmt.Errorf("page not found")
+	}
+
+	crop := inh.MediaBox
+	if inh.CropBox != nil {
+		crop = inh.CropBox
+	}
+
+	// Move the visible area to the origin and apply the page's own rotation,
+	// so m maps the page into the space it is displayed in.
+	m := translate(-crop.LL.X, -crop.LL.Y)
+	rot, w, h := rotation(inh.Rotate, crop.Width(), crop.Height())
+	m = m.compose(rot)
+
+	if layout.AutoRotate && w != h && (w > h) != (box.Width() > box.Height()) {
+		rot, w, h = rotation(270, w, h)
+		m = m.compose(rot)
+	}
+
+	sx, sy := box.Width()/w, box.Height()/h
+	scale := min(sx, sy)
+	if layout.Scale == ScaleFill {
+		scale = max(sx, sy)
+	}
+	dx := box.LL.X + (box.Width()-w*scale)/2
+	dy := box.LL.Y + (box.Height()-h*scale)/2
+	m = m.compose(affine{a: scale, d: scale, e: dx, f: dy})
+
+	// Whatever falls outside of the old visible area or the margins stays
+	// hidden.
+	clip := types.NewRectangle(
+		max(dx, box.LL.X), max(dy, box.LL.Y),
+		min(dx+w*scale, box.UR.X), min(dy+h*scale, box.UR.Y),
+	)
+
+	content, err := ctx.PageContent(d, page)
+	if err != nil && err != model.ErrNoContent {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "q %.5f %.5f %.5f %.5f re W n %s\n", clip.LL.X, clip.LL.Y, clip.Width(), clip.Height(), m)
+	b.Write(content)
+	b.WriteString("\nQ")
+
+	sd, err := ctx.NewStreamDictForBuf([]byte(b.String()))
+	if err != nil {
+		return err
+	}
+	if err := sd.Encode(); err != nil {
+		return err
+	}
+	ir, err := ctx.IndRefForNewObject(*sd)
+	if err != nil {
+		return err
+	}
+
+	d["Contents"] = *ir
+	// The crop box and rotation may be inherited from the page tree, so they
+	// are overridden rather than deleted.
+	paper := types.RectForDim(paperW, paperH).Array()
+	d["MediaBox"] = paper
+	d["CropBox"] = paper
+	d["Rotate"] = types.Integer(0)
+	for _, key := range []string{"BleedBox", "TrimBox", "ArtBox"} {
+		d.Delete(key)
+	}
+
+	return moveAnnotations(ctx, d, m)
+}
+
+// moveAnnotations maps the rectangle and quad points of every annotation of
+// a page through m.
+func moveAnnotations(ctx *model.Context, page types.Dict, m affine) error {
+	annots, err := ctx.DereferenceArray(page["Annots"])
+	if err != nil || annots == nil {
+		return nil
+	}
+
+	for _, obj := range annots {
+		annot, err := ctx.DereferenceDict(obj)
+		if err != nil || annot == nil {
+			continue
+		}
+
+		if rect, err := ctx.DereferenceArray(annot["Rect"]); err == nil && len(rect) == 4 {
+			r, err := ctx.RectForArray(rect)
+			if err != nil {
+				return err
+			}
+			x1, y1 := m.apply(r.LL.X, r.LL.Y)
+			x2, y2 := m.apply(r.UR.X, r.UR.Y)
+			annot["Rect"] = types.NewNumberArray(min(x1, x2), min(y1, y2), max(x1, x2), max(y1, y2))
+		}
+
+		if quads, err := ctx.DereferenceArray(annot["QuadPoints"]); err == nil && len(quads) > 0 && len(quads)%2 == 0 {
+			moved := make([]float64, len(quads))
+			for i := 0; i < len(quads); i += 2 {
+				x, err := ctx.DereferenceNumber(quads[i])
+				if err != nil {
+					return err
+				}
+				y, err := ctx.DereferenceNumber(quads[i+1])
+				if err != nil {
+					return err
+				}
+				moved[i], moved[i+1] = m.apply(x, y)
+			}
+			annot["QuadPoints"] = types.NewNumberArray(moved...)
+		}
+	}
+
+	return nil
+}
+
+// affine is a PDF transformation matrix [a b c d e f], mapping (x, y) to
+// (a·x + c·y + e, b·x + d·y + f).
+type affine struct {
+	a, b, c, d, e, f float64
+}
+
+// translate returns a matrix moving points by (dx, dy).
+func translate(dx, dy float64) affine {
+	return affine{a: 1, d: 1, e: dx, f: dy}
+}
+
+// rotation returns the matrix that turns a w × h page clockwise by deg, a
+// multiple of 90, as the /Rotate entry of a page does, along with the
+// dimensions of the turned page.
+func rotation(deg int, w, h float64) (affine, float64, float64) {
+	switch (deg%360 + 360) % 360 {
+	case 90:
+		return affine{b: -1, c: 1, f: w}, h, w
+	case 180:
+		return affine{a: -1, d: -1, e: w, f: h}, w, h
+	case 270:
+		return affine{b: 1, c: -1, e: h}, h, w
+	default:
+		return translate(0, 0), w, h
+	}
+}
+
+// compose returns the matrix applying m first and n second.
+func (m affine) compose(n affine) affine {
+	return affine{
+		a: m.a*n.a + m.b*n.c,
+		b: m.a*n.b + m.b*n.d,
+		c: m.c*n.a + m.d*n.c,
+		d: m.c*n.b + m.d*n.d,
+		e: m.e*n.a + m.f*n.c + n.e,
+		f: m.e*n.b + m.f*n.d + n.f,
+	}
+}
+
+// apply maps a point through m.
+func (m affine) apply(x, y float64) (float64, float64) {
+	return m.a*x + m.c*y + m.e, m.b*x + m.d*y + m.f
+}
+
+// String formats m as a content stream cm operator.
+func (m affine) String() string {
+	return fmt.Sprintf("%.5f %.5f %.5f %.5f %.5f %.5f cm", clean(m.a), clean(m.b), clean(m.c), clean(m.d), clean(m.e), clean(m.f))
+}
+
+// clean drops the sign of negative zero so matrices print as "0.00000".
+func clean(v float64) float64 {
+	if v == 0 || math.Abs(v) < 1e-9 {
+		return 0
+	}
+	return v
+}
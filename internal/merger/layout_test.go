@@ -0,0 +1,164 @@
+package merger
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// near reports whether a and b are equal up to rounding errors.
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestRotation(t *testing.T) {
+	const w, h = 200.0, 100.0
+
+	tests := []struct {
+		deg          int
+		wantW, wantH float64
+		// origin is where the lower-left corner of the page ends up.
+		originX, originY float64
+	}{
+		{0, w, h, 0, 0},
+		{90, h, w, 0, w},
+		{180, w, h, w, h},
+		{270, h, w, h, 0},
+		{-90, h, w, h, 0},
+		{450, h, w, 0, w},
+	}
+	for _, tt := range tests {
+		m, gotW, gotH := rotation(tt.deg, w, h)
+		if gotW != tt.wantW || gotH != tt.wantH {
+			t.Errorf("rotation(%d) turns the page into %v × %v, want %v × %v", tt.deg, gotW, gotH, tt.wantW, tt.wantH)
+		}
+		if x, y := m.apply(0, 0); !near(x, tt.originX) || !near(y, tt.originY) {
+			t.Errorf("rotation(%d) maps the origin to (%v, %v), want (%v, %v)", tt.deg, x, y, tt.originX, tt.originY)
+		}
+		// Every corner stays on the turned page.
+		for _, corner := range [][2]float64{{0, 0}, {w, 0}, {0, h}, {w, h}} {
+			x, y := m.apply(corner[0], corner[1])
+			if x < -1e-9 || y < -1e-9 || x > gotW+1e-9 || y > gotH+1e-9 {
+				t.Errorf("rotation(%d) maps corner %v outside the page to (%v, %v)", tt.deg, corner, x, y)
+			}
+		}
+	}
+}
+
+func TestAffineCompose(t *testing.T) {
+	scale := affine{a: 2, d: 2}
+	move := translate(10, 5)
+
+	// compose applies the receiver first.
+	if x, y := move.compose(scale).apply(1, 1); x != 22 || y != 12 {
+		t.Errorf("move then scale maps (1, 1) to (%v, %v), want (22, 12)", x, y)
+	}
+	if x, y := scale.compose(move).apply(1, 1); x != 12 || y != 7 {
+		t.Errorf("scale then move maps (1, 1) to (%v, %v), want (12, 7)", x, y)
+	}
+
+	// Turning four times by 90° is the identity.
+	m := translate(0, 0)
+	for range 4 {
+		r, _, _ := rotation(90, 100, 100)
+		m = m.compose(r)
+	}
+	if x, y := m.apply(30, 70); !near(x, 30) || !near(y, 70) {
+		t.Errorf("four quarter turns map (30, 70) to (%v, %v)", x, y)
+	}
+}
+
+func TestAffineString(t *testing.T) {
+	m, _, _ := rotation(90, 200, 100)
+	if got, want := m.String(), "0.00000 -1.00000 1.00000 0.00000 0.00000 200.00000 cm"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := (affine{a: math.Copysign(0, -1), d: 1}).String(), "0.00000 0.00000 0.00000 1.00000 0.00000 0.00000 cm"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestApplyLayout(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout PageLayout
+		// link is the expected rectangle of a link covering the lower-left
+		// 100 × 50 points of the original page.
+		link [4]float64
+		// check verifies the rectangle of a link covering the whole page.
+		check func(t *testing.T, r *types.Rectangle, paperW, paperH float64)
+	}{
+		{
+			name:   "auto rotate onto landscape",
+			layout: PageLayout{Landscape: true, AutoRotate: true},
+			link:   [4]float64{792, 0, 842, 100},
+			check: func(t *testing.T, r *types.Rectangle, paperW, paperH float64) {
+				if !near(r.LL.X, 0) || !near(r.LL.Y, 0) || !near(r.UR.X, paperW) || !near(r.UR.Y, paperH) {
+					t.Errorf("full page link = %v, want the whole paper", r)
+				}
+			},
+		},
+		{
+			name:   "fit inside margins",
+			layout: PageLayout{Margin: 10},
+			check: func(t *testing.T, r *types.Rectangle, paperW, paperH float64) {
+				left, right := r.LL.X, paperW-r.UR.X
+				bottom, top := r.LL.Y, paperH-r.UR.Y
+				if !near(left, right) || !near(bottom, top) {
+					t.Errorf("page is not centered: %v on %v × %v", r, paperW, paperH)
+				}
+				if !near(min(left, bottom), 10) {
+					t.Errorf("page does not touch the margin: %v", r)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := readTestPDF(t, testPDF(t, 1))
+			page, _, _, err := ctx.PageDict(1, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			corner := types.Dict{"Subtype": types.Name("Link"), "Rect": types.NewNumberArray(0, 0, 100, 50)}
+			full := types.Dict{"Subtype": types.Name("Link"), "Rect": types.NewNumberArray(0, 0, 595, 842)}
+			page["Annots"] = types.Array{corner, full}
+
+			if err := applyLayout(ctx, tt.layout); err != nil {
+				t.Fatal(err)
+			}
+
+			paperW, paperH, err := tt.layout.paper()
+			if err != nil {
+				t.Fatal(err)
+			}
+			media, err := ctx.RectForArray(page["MediaBox"].(types.Array))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !near(media.Width(), paperW) || !near(media.Height(), paperH) {
+				t.Errorf("media box = %v, want %v × %v", media, paperW, paperH)
+			}
+
+			rect := func(d types.Dict) *types.Rectangle {
+				r, err := ctx.RectForArray(d["Rect"].(types.Array))
+				if err != nil {
+					t.Fatal(err)
+				}
+				return r
+			}
+			if tt.link != [4]float64{} {
+				r := rect(corner)
+				got := [4]float64{r.LL.X, r.LL.Y, r.UR.X, r.UR.Y}
+				for i := range got {
+					if !near(got[i], tt.link[i]) {
+						t.Errorf("corner link = %v, want %v", got, tt.link)
+						break
+					}
+				}
+			}
+			tt.check(t, rect(full), paperW, paperH)
+		})
+	}
+}
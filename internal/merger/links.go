@@ -0,0 +1,240 @@
+package merger
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// pageLinkPrefix marks links that point at a page of the merged document.
+// Rendered HTML (such as the table of contents) links to these URLs, and
+// MergePDFs turns them into internal links once the final page numbers are
+// known. The .invalid TLD guarantees they never resolve on the network.
+const pageLinkPrefix = "https://rapid-pdf.invalid/page/"
+
+// PageLinkURL returns the placeholder URL of a link to the given page of the
+// merged document.
+func PageLinkURL(page int) string {
+	return pageLinkPrefix + strconv.Itoa(page)
+}
+
+// linkResolver maps the URI of a link annotation to an internal destination.
+// It returns false to leave the link untouched.
+type linkResolver func(uri string) (dest types.Object, ok bool, err error)
+
+// resolvePageLinks is a linkResolver for PageLinkURL placeholders.
+func resolvePageLinks(ctx *model.Context) linkResolver {
+	return func(uri string) (types.Object, bool, error) {
+		rest, ok := strings.CutPrefix(uri, pageLinkPrefix)
+		if !ok {
+			return nil, false, nil
+		}
+
+		page, err := strconv.Atoi(rest)
+		if err != nil || page < 1 || page > ctx.PageCount {
+			return nil, false, fmt.Errorf("invalid page link %q", uri)
+		}
+
+		dest, err := pageDest(ctx, page)
+		return dest, err == nil, err
+	}
+}
+
+// partTarget is where links to the URL of a merged part lead.
+type partTarget struct {
+	// first is the page of the merged document the part starts on.
+	first int
+	// dests maps the named destinations of the part, which Chrome creates
+	// for element IDs, to the page of the part and the view they show.
+	dests map[string]namedDest
+}
+
+// namedDest is a destination inside a part, relative to its first page.
+type namedDest struct {
+	page int
+	// view is the fit type and its parameters following the page in an
+	// explicit destination, e.g. [/XYZ 0 792 null].
+	view types.Array
+}
+
+// sourceKey normalizes the URL of a source, or of a link target, for
+// comparison: fragments and trailing slashes are ignored.
+func sourceKey(uri string) string {
+	base, _, _ := strings.Cut(uri, "#")
+	return strings.TrimRight(base, "/")
+}
+
+// newPartTarget records where a part starts in the merged document, given
+// its own context before merging.
+func newPartTarget(ctx *model.Context, first int) (*partTarget, error) {
+	target := &partTarget{first: first, dests: map[string]namedDest{}}
+
+	pages := make(map[int]int, ctx.PageCount)
+	for p := 1; p <= ctx.PageCount; p++ {
+		_, indRef, _, err := ctx.PageDict(p, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up page %d: %w", p, err)
+		}
+		if indRef != nil {
+			pages[indRef.ObjectNumber.Value()] = p
+		}
+	}
+
+	add := func(name string, obj types.Object) {
+		if d, err := ctx.DereferenceDict(obj); err == nil && d != nil {
+			obj = d["D"]
+		}
+		arr, err := ctx.DereferenceArray(obj)
+		if err != nil || len(arr) == 0 {
+			return
+		}
+		ref, ok := arr[0].(types.IndirectRef)
+		if !ok {
+			return
+		}
+		if page, ok := pages[ref.ObjectNumber.Value()]; ok {
+			target.dests[name] = namedDest{page: page, view: arr[1:]}
+		}
+	}
+
+	// Named destinations live in the catalog's Dests dictionary (PDF 1.1)
+	// or in the Dests name tree.
+	root, err := ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	if dests, err := ctx.DereferenceDict(root["Dests"]); err == nil {
+		for name, obj := range dests {
+			add(name, obj)
+		}
+	}
+	if err := ctx.LocateNameTree("Dests", false); err == nil && ctx.Names["Dests"] != nil {
+		err := ctx.Names["Dests"].Process(ctx.XRefTable, func(_ *model.XRefTable, name string, obj *types.Object) error {
+			add(name, *obj)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read named destinations: %w", err)
+		}
+	}
+
+	return target, nil
+}
+
+// resolveSourceLinks is a linkResolver for links to the URL of another part
+// of the merge. Links whose fragment names a destination of that part lead
+// there, all others to its first page. keepViews is false when the pages
+// were redrawn, so the coordinates of named destinations no longer apply and
+// their page is shown whole instead.
+func resolveSourceLinks(ctx *model.Context, targets map[string]*partTarget, keepViews bool) linkResolver {
+	return func(uri string) (types.Object, bool, error) {
+		target, ok := targets[sourceKey(uri)]
+		if !ok {
+			return nil, false, nil
+		}
+
+		if _, fragment, found := strings.Cut(uri, "#"); found && fragment != "" {
+			if name, err := url.PathUnescape(fragment); err == nil {
+				fragment = name
+			}
+			if nd, ok := target.dests[fragment]; ok {
+				dest, err := pageDest(ctx, target.first+nd.page-1)
+				if err != nil {
+					return nil, false, err
+				}
+				if keepViews && len(nd.view) > 0 {
+					dest = append(dest[:1], nd.view...)
+				}
+				return dest, true, nil
+			}
+		}
+
+		dest, err := pageDest(ctx, target.first)
+		return dest, err == nil, err
+	}
+}
+
+// pageDest returns an explicit destination showing the whole given page.
+func pageDest(ctx *model.Context, page int) (types.Array, error) {
+	_, indRef, _, err := ctx.PageDict(page, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up page %d: %w", page, err)
+	}
+	if indRef == nil {
+		return nil, fmt.Errorf("page %d not found", page)
+	}
+
+	return types.Array{*indRef, types.Name("Fit")}, nil
+}
+
+// rewriteLinks replaces the URI action of every link annotation accepted by
+// resolve with an internal destination. It returns the number of links
+// rewritten.
+func rewriteLinks(ctx *model.Context, resolve linkResolver) (int, error) {
+	rewritten := 0
+
+	for p := 1; p <= ctx.PageCount; p++ {
+		pageDict, _, _, err := ctx.PageDict(p, false)
+		if err != nil {
+			return rewritten, fmt.Errorf("failed to read page %d: %w", p, err)
+		}
+
+		annots, err := ctx.DereferenceArray(pageDict["Annots"])
+		if err != nil || annots == nil {
+			continue
+		}
+
+		for _, obj := range annots {
+			annot, err := ctx.DereferenceDict(obj)
+			if err != nil || annot == nil {
+				continue
+			}
+
+			uri, ok := linkURI(ctx, annot)
+			if !ok {
+				continue
+			}
+
+			dest, ok, err := resolve(uri)
+			if err != nil {
+				return rewritten, err
+			}
+			if !ok {
+				continue
+			}
+
+			annot.Delete("A")
+			annot["Dest"] = dest
+			rewritten++
+		}
+	}
+
+	return rewritten, nil
+}
+
+// linkURI returns the target of a link annotation with a URI action.
+func linkURI(ctx *model.Context, annot types.Dict) (string, bool) {
+	if subtype := annot.NameEntry("Subtype"); subtype == nil || *subtype != "Link" {
+		return "", false
+	}
+
+	action, err := ctx.DereferenceDict(annot["A"])
+	if err != nil || action == nil {
+		return "", false
+	}
+
+	if s := action.NameEntry("S"); s == nil || *s != "URI" {
+		return "", false
+	}
+
+	uri, err := ctx.DereferenceStringOrHexLiteral(action["URI"], model.V10, nil)
+	if err != nil {
+		return "", false
+	}
+
+	return uri, true
+}
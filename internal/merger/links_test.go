@@ -0,0 +1,156 @@
+package merger
+
+import (
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// destPage returns the page an explicit destination of ctx shows.
+func destPage(t *testing.T, ctx *model.Context, dest types.Object) int {
+	t.Helper()
+	arr, ok := dest.(types.Array)
+	if !ok || len(arr) == 0 {
+		t.Fatalf("destination %v is not an explicit destination", dest)
+	}
+	ref, ok := arr[0].(types.IndirectRef)
+	if !ok {
+		t.Fatalf("destination %v does not reference a page", dest)
+	}
+	for page := 1; page <= ctx.PageCount; page++ {
+		_, pageRef, _, err := ctx.PageDict(page, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pageRef != nil && pageRef.ObjectNumber == ref.ObjectNumber {
+			return page
+		}
+	}
+	t.Fatalf("destination %v references no page", dest)
+	return 0
+}
+
+func TestSourceKey(t *testing.T) {
+	tests := []struct {
+		uri, want string
+	}{
+		{"https://example.com/docs", "https://example.com/docs"},
+		{"https://example.com/docs/", "https://example.com/docs"},
+		{"https://example.com/docs#intro", "https://example.com/docs"},
+		{"https://example.com/docs/#intro", "https://example.com/docs"},
+		{"https://example.com/docs?page=2#intro", "https://example.com/docs?page=2"},
+		{"https://example.com/", "https://example.com"},
+	}
+	for _, tt := range tests {
+		if got := sourceKey(tt.uri); got != tt.want {
+			t.Errorf("sourceKey(%q) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+}
+
+func TestResolvePageLinks(t *testing.T) {
+	ctx := readTestPDF(t, testPDF(t, 3))
+	resolve := resolvePageLinks(ctx)
+
+	dest, ok, err := resolve(PageLinkURL(2))
+	if err != nil || !ok {
+		t.Fatalf("resolve(PageLinkURL(2)) = %v, %v", ok, err)
+	}
+	if page := destPage(t, ctx, dest); page != 2 {
+		t.Errorf("link leads to page %d, want 2", page)
+	}
+
+	if _, ok, err := resolve("https://example.com/page/2"); ok || err != nil {
+		t.Errorf("external link resolved: %v, %v", ok, err)
+	}
+
+	for _, uri := range []string{PageLinkURL(0), PageLinkURL(4), pageLinkPrefix + "two"} {
+		if _, _, err := resolve(uri); err == nil {
+			t.Errorf("resolve(%q) succeeded", uri)
+		}
+	}
+}
+
+func TestResolveSourceLinks(t *testing.T) {
+	ctx := readTestPDF(t, testPDF(t, 5))
+	view := types.Array{types.Name("XYZ"), types.Integer(0), types.Integer(700), nil}
+	targets := map[string]*partTarget{
+		"https://example.com/guide": {
+			first: 2,
+			dests: map[string]namedDest{"setup step": {page: 3, view: view}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		uri       string
+		keepViews bool
+		wantOK    bool
+		wantPage  int
+		wantView  bool
+	}{
+		{"first page", "https://example.com/guide/", true, true, 2, false},
+		{"named destination", "https://example.com/guide#setup%20step", true, true, 4, true},
+		{"redrawn pages", "https://example.com/guide#setup%20step", false, true, 4, false},
+		{"unknown fragment", "https://example.com/guide#missing", true, true, 2, false},
+		{"other source", "https://example.com/other", true, false, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest, ok, err := resolveSourceLinks(ctx, targets, tt.keepViews)(tt.uri)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if page := destPage(t, ctx, dest); page != tt.wantPage {
+				t.Errorf("link leads to page %d, want %d", page, tt.wantPage)
+			}
+			arr := dest.(types.Array)
+			if gotView := arr[1] == types.Name("XYZ"); gotView != tt.wantView {
+				t.Errorf("destination %v keeps the view: %v, want %v", arr, gotView, tt.wantView)
+			}
+		})
+	}
+}
+
+func TestRewriteLinks(t *testing.T) {
+	ctx := readTestPDF(t, testPDF(t, 3))
+	page, _, _, err := ctx.PageDict(1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	link := func(uri string) types.Dict {
+		return types.Dict{
+			"Type":    types.Name("Annot"),
+			"Subtype": types.Name("Link"),
+			"Rect":    types.NewNumberArray(0, 0, 10, 10),
+			"A":       types.Dict{"S": types.Name("URI"), "URI": types.StringLiteral(uri)},
+		}
+	}
+	internal, external := link(PageLinkURL(3)), link("https://example.com")
+	page["Annots"] = types.Array{internal, external}
+
+	n, err := rewriteLinks(ctx, resolvePageLinks(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("rewrote %d links, want 1", n)
+	}
+	if _, ok := internal["A"]; ok {
+		t.Error("rewritten link kept its URI action")
+	}
+	if got := destPage(t, ctx, internal["Dest"]); got != 3 {
+		t.Errorf("rewritten link leads to page %d, want 3", got)
+	}
+	if _, ok := external["Dest"]; ok {
+		t.Error("external link was rewritten")
+	}
+}
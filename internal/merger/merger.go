@@ -2,58 +2,311 @@ package merger
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
-	"path/filepath"
 
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
 )
 
-// MergePDFs combines multiple PDF files into a single output PDF.
-// It uses pdfcpu for reliable, pure-Go PDF merging.
-func MergePDFs(inputFiles []string, outputFile string) error {
-	if len(inputFiles) == 0 {
+// Part is a single PDF to merge together with the title of its bookmark.
+type Part struct {
+	PDF io.ReadSeeker
+	// Title is the caller-supplied bookmark label. When empty, the title
+	// from the PDF's info dictionary (the page <title> for Chrome output)
+	// is used, falling back to Name.
+	Title string
+	// Name identifies the part when it has no title, e.g. its URL.
+	Name string
+	// URL is the address the part was rendered from, if any. Links to it
+	// from other parts become internal links to the part.
+	URL string
+	// Separator, when set, is merged right before the part. The first part
+	// of a merge never gets one.
+	Separator *Separator
+}
+
+// Separator holds the pages inserted between two parts.
+type Separator struct {
+	// PDF holds the separator pages, such as a page announcing the title of
+	// the next part. A nil PDF inserts a single blank page.
+	PDF io.ReadSeeker
+}
+
+// MergeOptions controls how parts are put together.
+type MergeOptions struct {
+	// Layout, when set, redraws every page onto the same paper size.
+	Layout *PageLayout
+	// Duplex inserts blank pages so every part, and every separator, starts
+	// on an odd, right-hand page when printed double-sided.
+	Duplex bool
+}
+
+// Padding returns the number of blank pages Duplex inserts after a document
+// of the given length, so that what follows starts on a right-hand page.
+func (o MergeOptions) Padding(pages int) int {
+	if o.Duplex && pages%2 == 1 {
+		return 1
+	}
+	return 0
+}
+
+// Section describes where a part ends up in the merged document.
+type Section struct {
+	Title    string
+	PageFrom int
+	PageThru int
+}
+
+// MergePDFs combines multiple PDFs into a single PDF written to w.
+// It uses pdfcpu for reliable, pure-Go PDF merging. Every part gets a
+// top-level bookmark pointing at its first page, with the part's own
+// outline nested underneath, and PageLinkURL links as well as links to the
+// URL of another part become internal links. Separators and duplex padding
+// are inserted between parts as requested.
+func MergePDFs(parts []Part, opts MergeOptions, w io.Writer) error {
+	if len(parts) == 0 {
 		return fmt.Errorf("no input files to merge")
 	}
 
-	slog.Info("merging PDFs", "input_count", len(inputFiles), "output", outputFile)
+	slog.Info("merging PDFs", "input_count", len(parts))
+
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.MERGECREATE
+	conf.ValidationMode = model.ValidationRelaxed
+
+	ctxDest, err := readPDF(parts[0].PDF, conf)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", parts[0].title(nil), err)
+	}
+
+	// The first part becomes the destination, so its outline is wrapped in
+	// its own bookmark here; the other parts get theirs while being merged.
+	if err := pdfcpu.EnsureOutlines(ctxDest, parts[0].title(ctxDest), false); err != nil {
+		return fmt.Errorf("failed to create bookmarks: %w", err)
+	}
+	ctxDest.EnsureVersionForWriting()
+
+	// targets maps the URL of every part to where it starts.
+	targets := map[string]*partTarget{}
+	addTarget := func(part Part, ctx *model.Context, first int) error {
+		key := sourceKey(part.URL)
+		if key == "" || targets[key] != nil {
+			return nil
+		}
+		target, err := newPartTarget(ctx, first)
+		if err != nil {
+			return fmt.Errorf("failed to index %s: %w", part.title(ctx), err)
+		}
+		targets[key] = target
+		return nil
+	}
+	if err := addTarget(parts[0], ctxDest, 1); err != nil {
+		return err
+	}
+
+	// pad starts whatever comes next on a right-hand page.
+	blanks := 0
+	pad := func() error {
+		n := opts.Padding(ctxDest.PageCount)
+		blanks += n
+		return appendBlankPages(ctxDest, n)
+	}
+
+	for _, part := range parts[1:] {
+		if sep := part.Separator; sep != nil {
+			if err := pad(); err != nil {
+				return err
+			}
+			if err := appendSeparator(ctxDest, *sep, conf); err != nil {
+				return fmt.Errorf("failed to insert separator before %s: %w", part.title(nil), err)
+			}
+		}
+		if err := pad(); err != nil {
+			return err
+		}
+
+		ctxSrc, err := readPDF(part.PDF, conf)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", part.title(nil), err)
+		}
+
+		if ctxDest.XRefTable.Version() < model.V20 && ctxSrc.XRefTable.Version() == model.V20 {
+			return fmt.Errorf("failed to merge %s: %w", part.title(ctxSrc), pdfcpu.ErrUnsupportedVersion)
+		}
+
+		if err := addTarget(part, ctxSrc, ctxDest.PageCount+1); err != nil {
+			return err
+		}
+		if err := pdfcpu.MergeXRefTables(part.title(ctxSrc), ctxSrc, ctxDest, false, false); err != nil {
+			return fmt.Errorf("failed to merge PDFs: %w", err)
+		}
+	}
+
+	if blanks > 0 {
+		slog.Info("inserted duplex padding", "blank_pages", blanks)
+	}
+
+	if layout := opts.Layout; layout != nil {
+		if err := applyLayout(ctxDest, *layout); err != nil {
+			return fmt.Errorf("failed to normalize page size: %w", err)
+		}
+		slog.Info("pages normalized", "paper_size", layout.paperSize(), "landscape", layout.Landscape, "scale", layout.Scale)
+	}
+
+	links, err := rewriteLinks(ctxDest, resolvePageLinks(ctxDest))
+	if err != nil {
+		return fmt.Errorf("failed to resolve page links: %w", err)
+	}
+	if links > 0 {
+		slog.Info("resolved internal page links", "count", links)
+	}
+
+	if len(targets) > 0 {
+		links, err := rewriteLinks(ctxDest, resolveSourceLinks(ctxDest, targets, opts.Layout == nil))
+		if err != nil {
+			return fmt.Errorf("failed to resolve cross-document links: %w", err)
+		}
+		if links > 0 {
+			slog.Info("resolved cross-document links", "count", links)
+		}
+	}
+
+	if err := api.WriteContext(ctxDest, w); err != nil {
+		return fmt.Errorf("failed to write merged PDF: %w", err)
+	}
+
+	slog.Info("merge complete", "pages", ctxDest.PageCount)
+	return nil
+}
+
+// Sections reports the title and page range each part will occupy when the
+// parts are merged in order behind firstPage-1 pages, accounting for the
+// separators and duplex padding of opts.
+func Sections(parts []Part, opts MergeOptions, firstPage int) ([]Section, error) {
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+
+	sections := make([]Section, len(parts))
+	pages := firstPage - 1
+	for i, part := range parts {
+		if sep := part.Separator; sep != nil && i > 0 {
+			pages += opts.Padding(pages)
+			n := 1
+			if sep.PDF != nil {
+				var err error
+				if n, err = PageCount(sep.PDF); err != nil {
+					return nil, fmt.Errorf("failed to read separator before %s: %w", part.title(nil), err)
+				}
+			}
+			pages += n
+		}
+		pages += opts.Padding(pages)
+
+		ctx, err := readPDF(part.PDF, conf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", part.title(nil), err)
+		}
 
-	// Ensure the output directory exists.
-	outputDir := filepath.Dir(outputFile)
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+		sections[i] = Section{
+			Title:    part.title(ctx),
+			PageFrom: pages + 1,
+			PageThru: pages + ctx.PageCount,
+		}
+		pages += ctx.PageCount
 	}
 
-	// Use pdfcpu's MergeCreateFile to combine all PDFs.
-	// The last argument (false) means we don't want to optimize/compress.
-	if err := api.MergeCreateFile(inputFiles, outputFile, false, nil); err != nil {
-		return fmt.Errorf("failed to merge PDFs: %w", err)
+	return sections, nil
+}
+
+// PageCount returns the number of pages of a PDF.
+func PageCount(rs io.ReadSeeker) (int, error) {
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+
+	n, err := api.PageCount(rs, conf)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pages: %w", err)
 	}
+	return n, nil
+}
 
-	// Get the size of the merged file for logging.
-	info, err := os.Stat(outputFile)
-	if err == nil {
-		slog.Info("merge complete",
-			"output", outputFile,
-			"size_mb", fmt.Sprintf("%.2f", float64(info.Size())/(1024*1024)),
-		)
+// appendSeparator merges the separator pages at the end of ctx without a
+// bookmark of their own.
+func appendSeparator(ctx *model.Context, sep Separator, conf *model.Configuration) error {
+	if sep.PDF == nil {
+		return appendBlankPages(ctx, 1)
 	}
 
+	ctxSrc, err := readPDF(sep.PDF, conf)
+	if err != nil {
+		return err
+	}
+
+	bookmarks := ctx.Configuration.CreateBookmarks
+	ctx.Configuration.CreateBookmarks = false
+	defer func() { ctx.Configuration.CreateBookmarks = bookmarks }()
+
+	return pdfcpu.MergeXRefTables("", ctxSrc, ctx, false, false)
+}
+
+// appendBlankPages adds n blank pages, the size of the last page, at the end
+// of ctx.
+func appendBlankPages(ctx *model.Context, n int) error {
+	for range n {
+		if err := ctx.InsertBlankPages(types.IntSet{ctx.PageCount: true}, nil, false); err != nil {
+			return fmt.Errorf("failed to insert blank page: %w", err)
+		}
+		ctx.PageCount++
+	}
 	return nil
 }
 
-// Cleanup removes the temporary PDF files and their parent directory.
-func Cleanup(files []string) {
-	if len(files) == 0 {
-		return
+// readPart reads and validates a single PDF file into a pdfcpu context.
+func readPart(path string, conf *model.Configuration) (*model.Context, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	ctx, err := readPDF(f, conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
 	}
 
-	// All temp files should be in the same directory.
-	tmpDir := filepath.Dir(files[0])
+	return ctx, nil
+}
+
+// readPDF reads and validates a PDF from its start into a pdfcpu context.
+func readPDF(rs io.ReadSeeker, conf *model.Configuration) (*model.Context, error) {
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return api.ReadAndValidate(rs, conf)
+}
+
+// passThrough copies a PDF that needs no changes from rs to w.
+func passThrough(rs io.ReadSeeker, w io.Writer) error {
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.Copy(w, rs)
+	return err
+}
 
-	if err := os.RemoveAll(tmpDir); err != nil {
-		slog.Warn("failed to clean up temp directory", "dir", tmpDir, "error", err)
-	} else {
-		slog.Info("cleaned up temp files", "dir", tmpDir)
+// title resolves the bookmark title of the part. ctx may be nil when the
+// part could not be read.
+func (p Part) title(ctx *model.Context) string {
+	switch {
+	case p.Title != "":
+		return p.Title
+	case ctx != nil && ctx.Title != "":
+		return ctx.Title
+	default:
+		return p.Name
 	}
 }
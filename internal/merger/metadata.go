@@ -0,0 +1,334 @@
+package merger
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Metadata describes the document information written to a PDF, both to its
+// info dictionary and to an XMP metadata stream. Empty fields leave the
+// existing value untouched.
+type Metadata struct {
+	Title    string
+	Author   string
+	Subject  string
+	Keywords []string
+	Creator  string
+	Producer string
+	// Language is the natural language of the document as a BCP 47 tag,
+	// e.g. "pt-BR".
+	Language string
+	// Properties are custom entries added to the info dictionary.
+	Properties map[string]string
+	// PDFA declares PDF/A-2b conformance in the XMP packet. The document
+	// must have been prepared by ConvertPDFA.
+	PDFA bool
+}
+
+// standardInfoKeys are the info dictionary entries managed by Metadata or by
+// pdfcpu, which custom properties may not override.
+var standardInfoKeys = map[string]bool{
+	"Title":        true,
+	"Author":       true,
+	"Subject":      true,
+	"Keywords":     true,
+	"Creator":      true,
+	"Producer":     true,
+	"CreationDate": true,
+	"ModDate":      true,
+	"Trapped":      true,
+}
+
+// IsZero reports whether m does not set anything.
+func (m Metadata) IsZero() bool {
+	return m.Title == "" && m.Author == "" && m.Subject == "" && len(m.Keywords) == 0 &&
+		m.Creator == "" && m.Producer == "" && m.Language == "" && len(m.Properties) == 0 && !m.PDFA
+}
+
+// Validate checks that every custom property has a usable name.
+func (m Metadata) Validate() error {
+	for key := range m.Properties {
+		switch {
+		case key == "":
+			return fmt.Errorf("property name must not be empty")
+		case standardInfoKeys[key]:
+			return fmt.Errorf("property %q is reserved, use the dedicated field", key)
+		case strings.ContainsAny(key, " \t\r\n/()<>[]{}%#"):
+			return fmt.Errorf("property name %q contains invalid characters", key)
+		}
+	}
+	return nil
+}
+
+// SetMetadata writes meta to the PDF in rws as an incremental update. It
+// fills the info dictionary, sets the catalog language and attaches an XMP
+// packet mirroring the final document information. Appending an update keeps
+// the Producer intact, which pdfcpu overwrites whenever it rewrites a file.
+// Encrypted documents are opened with password, their user password, and
+// the update is encrypted like the rest of the file.
+func SetMetadata(rws io.ReadWriteSeeker, meta Metadata, password string) error {
+	if err := meta.Validate(); err != nil {
+		return err
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+	conf.UserPW = password
+	// Validating again before the write drops the name trees, such as the
+	// named destinations, from the catalog written to the update.
+	conf.PostProcessValidate = false
+
+	ctx, err := readPDF(rws, conf)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	ctx.Write.Increment = true
+	ctx.Write.Offset = ctx.Read.FileSize
+
+	now := time.Now()
+
+	info, err := updateInfoDict(ctx, meta, now)
+	if err != nil {
+		return fmt.Errorf("failed to update info dictionary: %w", err)
+	}
+
+	if err := updateCatalog(ctx, info, meta.Language); err != nil {
+		return fmt.Errorf("failed to update document catalog: %w", err)
+	}
+
+	if err := api.WriteIncr(ctx, rws, conf); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+
+	slog.Info("document metadata written", "title", info.Title, "language", meta.Language)
+	return nil
+}
+
+// documentInfo is the resolved document information mirrored into XMP.
+type documentInfo struct {
+	Title    string
+	Author   string
+	Subject  string
+	Keywords string
+	Creator  string
+	Producer string
+	Created  string
+	Modified string
+	PDFA     bool
+}
+
+// updateInfoDict applies meta to the info dictionary, creating it when the
+// document has none, and returns the resulting document information.
+func updateInfoDict(ctx *model.Context, meta Metadata, now time.Time) (documentInfo, error) {
+	if ctx.Info == nil {
+		ir, err := ctx.IndRefForNewObject(types.NewDict())
+		if err != nil {
+			return documentInfo{}, err
+		}
+		ctx.Info = ir
+	}
+
+	d, err := ctx.DereferenceDict(*ctx.Info)
+	if err != nil || d == nil {
+		return documentInfo{}, fmt.Errorf("invalid info dictionary: %w", err)
+	}
+
+	entries := map[string]string{
+		"Title":    meta.Title,
+		"Author":   meta.Author,
+		"Subject":  meta.Subject,
+		"Keywords": strings.Join(meta.Keywords, ", "),
+		"Creator":  meta.Creator,
+		"Producer": meta.Producer,
+	}
+	for key, value := range meta.Properties {
+		entries[key] = value
+	}
+	for key, value := range entries {
+		if value == "" {
+			continue
+		}
+		s, err := pdfText(value)
+		if err != nil {
+			return documentInfo{}, err
+		}
+		d[key] = s
+	}
+	d["ModDate"] = types.StringLiteral(types.DateString(now))
+	if _, ok := d["CreationDate"]; !ok {
+		d["CreationDate"] = d["ModDate"]
+	}
+
+	ctx.Write.IncrementWithObjNr(ctx.Info.ObjectNumber.Value())
+
+	created := now
+	if t, ok := infoDate(ctx, d, "CreationDate"); ok {
+		created = t
+	}
+
+	return documentInfo{
+		Title:    infoText(ctx, d, "Title"),
+		Author:   infoText(ctx, d, "Author"),
+		Subject:  infoText(ctx, d, "Subject"),
+		Keywords: infoText(ctx, d, "Keywords"),
+		Creator:  infoText(ctx, d, "Creator"),
+		Producer: infoText(ctx, d, "Producer"),
+		Created:  created.Format(time.RFC3339),
+		Modified: now.Format(time.RFC3339),
+		PDFA:     meta.PDFA,
+	}, nil
+}
+
+// updateCatalog sets the document language and points the catalog at a new
+// XMP metadata stream built from info.
+func updateCatalog(ctx *model.Context, info documentInfo, language string) error {
+	root, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+
+	if language != "" {
+		lang, err := pdfText(language)
+		if err != nil {
+			return err
+		}
+		root["Lang"] = lang
+	}
+
+	packet, err := xmpPacket(info)
+	if err != nil {
+		return err
+	}
+
+	sd := types.StreamDict{Dict: types.NewDict(), Content: packet}
+	sd.InsertName("Type", "Metadata")
+	sd.InsertName("Subtype", "XML")
+	if err := sd.Encode(); err != nil {
+		return err
+	}
+
+	ir, err := ctx.IndRefForNewObject(sd)
+	if err != nil {
+		return err
+	}
+	root["Metadata"] = *ir
+
+	ctx.Write.IncrementWithObjNr(ir.ObjectNumber.Value())
+	ctx.Write.IncrementWithObjNr(ctx.Root.ObjectNumber.Value())
+	return nil
+}
+
+// pdfText encodes s as a PDF text string, using UTF-16 only when s is not
+// plain ASCII.
+func pdfText(s string) (types.StringLiteral, error) {
+	if !utf8.ValidString(s) {
+		return "", fmt.Errorf("%q is not valid UTF-8", s)
+	}
+
+	encode := types.Escape
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			encode = types.EscapedUTF16String
+			break
+		}
+	}
+
+	escaped, err := encode(s)
+	if err != nil {
+		return "", err
+	}
+	return types.StringLiteral(*escaped), nil
+}
+
+// infoText returns the decoded text of an info dictionary entry.
+func infoText(ctx *model.Context, d types.Dict, key string) string {
+	o, ok := d[key]
+	if !ok {
+		return ""
+	}
+	s, err := ctx.DereferenceText(o)
+	if err != nil {
+		return ""
+	}
+	return s
+}
+
+// infoDate returns the parsed date of an info dictionary entry.
+func infoDate(ctx *model.Context, d types.Dict, key string) (time.Time, bool) {
+	s := infoText(ctx, d, key)
+	if s == "" {
+		return time.Time{}, false
+	}
+	return types.DateTime(s, true)
+}
+
+// xmpTemplate renders the XMP packet embedded as the document metadata stream.
+var xmpTemplate = template.Must(template.New("xmp").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(
+	`<?xpacket begin="` + "\ufeff" + `" id="W5M0MpCehiHzreSzNTczkc9d"?>
+<x:xmpmeta xmlns:x="adobe:ns:meta/">
+ <rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+  <rdf:Description rdf:about=""
+    xmlns:dc="http://purl.org/dc/elements/1.1/"
+    xmlns:xmp="http://ns.adobe.com/xap/1.0/"
+    xmlns:pdf="http://ns.adobe.com/pdf/1.3/"
+    xmlns:pdfaid="http://www.aiim.org/pdfa/ns/id/">
+   <dc:format>application/pdf</dc:format>
+{{- with .Title}}
+   <dc:title><rdf:Alt><rdf:li xml:lang="x-default">{{xml .}}</rdf:li></rdf:Alt></dc:title>
+{{- end}}
+{{- with .Author}}
+   <dc:creator><rdf:Seq><rdf:li>{{xml .}}</rdf:li></rdf:Seq></dc:creator>
+{{- end}}
+{{- with .Subject}}
+   <dc:description><rdf:Alt><rdf:li xml:lang="x-default">{{xml .}}</rdf:li></rdf:Alt></dc:description>
+{{- end}}
+{{- with .Keywords}}
+   <pdf:Keywords>{{xml .}}</pdf:Keywords>
+{{- end}}
+{{- with .Creator}}
+   <xmp:CreatorTool>{{xml .}}</xmp:CreatorTool>
+{{- end}}
+{{- with .Producer}}
+   <pdf:Producer>{{xml .}}</pdf:Producer>
+{{- end}}
+   <xmp:CreateDate>{{.Created}}</xmp:CreateDate>
+   <xmp:ModifyDate>{{.Modified}}</xmp:ModifyDate>
+   <xmp:MetadataDate>{{.Modified}}</xmp:MetadataDate>
+{{- if .PDFA}}
+   <pdfaid:part>2</pdfaid:part>
+   <pdfaid:conformance>B</pdfaid:conformance>
+{{- end}}
+  </rdf:Description>
+ </rdf:RDF>
+</x:xmpmeta>
+<?xpacket end="w"?>`))
+
+// xmpPacket renders the XMP metadata for info.
+func xmpPacket(info documentInfo) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := xmpTemplate.Execute(&buf, info); err != nil {
+		return nil, fmt.Errorf("failed to render XMP: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// xmlEscape escapes s for use as XML character data.
+func xmlEscape(s string) (string, error) {
+	var buf strings.Builder
+	if err := xml.EscapeText(&buf, []byte(s)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,176 @@
+package merger
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+const (
+	// DefaultPageNumberFormat is the label stamped when no format is set.
+	DefaultPageNumberFormat = "Page {page} of {total}"
+
+	defaultPageNumberPosition = "bc"
+	defaultPageNumberFontSize = 10
+	// defaultPageNumberMargin keeps labels clear of the page edge, in points.
+	defaultPageNumberMargin = 20
+)
+
+// PageNumbering stamps continuous page numbers over a merged document, which
+// Chrome cannot do as it prints every source on its own.
+type PageNumbering struct {
+	// Format is the label of each page, where {page} expands to the page
+	// number and {total} to the number of the last page. Defaults to
+	// DefaultPageNumberFormat.
+	Format string
+	// Position is one of Positions. Defaults to "bc".
+	Position string
+	FontName string
+	// FontSize defaults to 10 points.
+	FontSize int
+	// Color is a hex color such as "#444444".
+	Color string
+	// Margin is the distance of the label from the page edge in points.
+	// Defaults to 20.
+	Margin float64
+	// Start is the number of the first numbered page. Defaults to 1.
+	Start int
+	// Skip leaves the first pages, such as a cover or a table of contents,
+	// unnumbered.
+	Skip int
+}
+
+// Validate checks that the page numbers can be stamped.
+func (p PageNumbering) Validate() error {
+	if p.Start < 0 || p.Skip < 0 || p.Margin < 0 {
+		return fmt.Errorf("page numbering start, skip and margin must not be negative")
+	}
+	if p.Position != "" && !slices.Contains(Positions, p.Position) {
+		return fmt.Errorf("invalid page number position %q", p.Position)
+	}
+	return p.watermark(p.label(1, 1)).Validate()
+}
+
+// Number returns the number stamped on page, counting from 1, and whether
+// the page is numbered at all.
+func (p PageNumbering) Number(page int) (int, bool) {
+	if page <= p.Skip {
+		return 0, false
+	}
+	return cmp.Or(p.Start, 1) + page - p.Skip - 1, true
+}
+
+// label renders the label of page in a document of pageCount pages.
+func (p PageNumbering) label(page, pageCount int) string {
+	n, _ := p.Number(page)
+	total, _ := p.Number(pageCount)
+	return strings.NewReplacer(
+		"{page}", strconv.Itoa(n),
+		"{total}", strconv.Itoa(total),
+	).Replace(cmp.Or(p.Format, DefaultPageNumberFormat))
+}
+
+// watermark returns the stamp drawing text, placed at the numbering position
+// and moved away from the page edges by the margin.
+func (p PageNumbering) watermark(text string) Watermark {
+	position := cmp.Or(p.Position, defaultPageNumberPosition)
+	margin := p.Margin
+	if margin == 0 {
+		margin = defaultPageNumberMargin
+	}
+
+	var dx, dy float64
+	switch position[0] {
+	case 't':
+		dy = -margin
+	case 'b':
+		dy = margin
+	}
+	switch position[len(position)-1] {
+	case 'l':
+		dx = margin
+	case 'r':
+		dx = -margin
+	}
+
+	var rotation float64
+	return Watermark{
+		Text:     text,
+		OnTop:    true,
+		FontName: p.FontName,
+		FontSize: cmp.Or(p.FontSize, defaultPageNumberFontSize),
+		Color:    p.Color,
+		Rotation: &rotation,
+		Position: position,
+		OffsetX:  dx,
+		OffsetY:  dy,
+	}
+}
+
+// AddPageNumbers stamps the page numbers over every numbered page of the PDF
+// read from rs and writes the result to w.
+func AddPageNumbers(rs io.ReadSeeker, w io.Writer, numbering PageNumbering) error {
+	if err := numbering.Validate(); err != nil {
+		return err
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.ADDWATERMARKS
+	conf.ValidationMode = model.ValidationRelaxed
+
+	ctx, err := readPDF(rs, conf)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	pages := types.IntSet{}
+	for page := numbering.Skip + 1; page <= ctx.PageCount; page++ {
+		pages[page] = true
+	}
+	if len(pages) > 0 {
+		if err := numbering.stamp(ctx, pages); err != nil {
+			return fmt.Errorf("failed to stamp page numbers: %w", err)
+		}
+	}
+
+	if err := api.WriteContext(ctx, w); err != nil {
+		return fmt.Errorf("failed to write numbered PDF: %w", err)
+	}
+
+	slog.Info("page numbers stamped", "pages", len(pages), "skipped", min(numbering.Skip, ctx.PageCount))
+	return nil
+}
+
+// stamp adds the labels of pages to ctx in a single pass, sharing the font
+// and graphics state between them. When every number matches the position of
+// its page, one watermark is stamped with pdfcpu's %p and %P placeholders;
+// otherwise each page gets its own rendered label.
+func (p PageNumbering) stamp(ctx *model.Context, pages types.IntSet) error {
+	if n, _ := p.Number(ctx.PageCount); n == ctx.PageCount {
+		format := strings.NewReplacer("{page}", "%p", "{total}", "%P").Replace(cmp.Or(p.Format, DefaultPageNumberFormat))
+		wm, err := p.watermark(format).model()
+		if err != nil {
+			return err
+		}
+		return api.WatermarkContext(ctx, pages, wm)
+	}
+
+	m := make(map[int]*model.Watermark, len(pages))
+	for page := range pages {
+		wm, err := p.watermark(p.label(page, ctx.PageCount)).model()
+		if err != nil {
+			return err
+		}
+		m[page] = wm
+	}
+	return pdfcpu.AddWatermarksMap(ctx, m)
+}
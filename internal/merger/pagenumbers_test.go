@@ -0,0 +1,83 @@
+package merger
+
+import (
+	"bytes"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+var labelPattern = regexp.MustCompile(`\(([^()]*)\) Tj`)
+
+func TestAddPageNumbers(t *testing.T) {
+	tests := []struct {
+		name      string
+		numbering PageNumbering
+		want      []string
+	}{
+		{
+			name: "default",
+			want: []string{"Page 1 of 4", "Page 2 of 4", "Page 3 of 4", "Page 4 of 4"},
+		},
+		{
+			name:      "skipped cover",
+			numbering: PageNumbering{Format: "{page}/{total}", Skip: 1},
+			want:      []string{"1/3", "2/3", "3/3"},
+		},
+		{
+			name:      "start after skipped pages",
+			numbering: PageNumbering{Format: "{page}", Skip: 2, Start: 3},
+			want:      []string{"3", "4"},
+		},
+		{
+			name:      "custom start",
+			numbering: PageNumbering{Start: 10},
+			want:      []string{"Page 10 of 13", "Page 11 of 13", "Page 12 of 13", "Page 13 of 13"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := AddPageNumbers(bytes.NewReader(testPDF(t, 4)), &out, tt.numbering); err != nil {
+				t.Fatalf("AddPageNumbers: %v", err)
+			}
+
+			ctx := readTestPDF(t, out.Bytes())
+			var labels []string
+			fonts := 0
+			for _, entry := range ctx.Table {
+				if entry == nil || entry.Free {
+					continue
+				}
+				switch o := entry.Object.(type) {
+				case types.Dict:
+					if o.Type() != nil && *o.Type() == "Font" {
+						fonts++
+					}
+				case types.StreamDict:
+					if err := o.Decode(); err != nil {
+						t.Fatal(err)
+					}
+					// The test pages draw "Page n" themselves, the
+					// stamps are form XObjects.
+					if o.Subtype() != nil && *o.Subtype() == "Form" {
+						for _, m := range labelPattern.FindAllSubmatch(o.Content, -1) {
+							labels = append(labels, string(m[1]))
+						}
+					}
+				}
+			}
+
+			slices.Sort(labels)
+			want := slices.Sorted(slices.Values(tt.want))
+			if !slices.Equal(labels, want) {
+				t.Errorf("labels = %q, want %q", labels, want)
+			}
+			if fonts != 1 {
+				t.Errorf("%d fonts added, want one shared by every label", fonts)
+			}
+		})
+	}
+}
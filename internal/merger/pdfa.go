@@ -0,0 +1,447 @@
+package merger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// PDF/A-2b conformance checks reported by CheckPDFA.
+const (
+	CheckVersion       = "version"
+	CheckEncryption    = "encryption"
+	CheckOutputIntent  = "output_intent"
+	CheckXMPMetadata   = "xmp_metadata"
+	CheckFonts         = "fonts_embedded"
+	CheckAnnotations   = "annotations"
+	CheckTransparency  = "transparency"
+	CheckJavaScript    = "javascript"
+	CheckEmbeddedFiles = "embedded_files"
+	CheckSyntax        = "syntax"
+)
+
+// srgbOutputCondition identifies the output intent added by ConvertPDFA.
+const srgbOutputCondition = "sRGB IEC61966-2.1"
+
+// ConformanceCheck is the outcome of one PDF/A requirement.
+type ConformanceCheck struct {
+	// Name is one of the Check* constants.
+	Name   string
+	Passed bool
+	// Detail explains a failed check.
+	Detail string
+}
+
+// ConvertPDFA prepares the PDF read from rs for PDF/A-2b and writes the
+// result to w: it adds an sRGB output intent and makes every annotation
+// printable. The matching XMP identification is written by SetMetadata with
+// Metadata.PDFA set, which must run afterwards. Encrypted documents and PDF
+// 2.0 files cannot conform and are rejected.
+func ConvertPDFA(rs io.ReadSeeker, w io.Writer) error {
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+
+	ctx, err := readPDF(rs, conf)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+	if ctx.Encrypt != nil {
+		return fmt.Errorf("encrypted documents cannot conform to PDF/A")
+	}
+	if ctx.XRefTable.Version() == model.V20 {
+		return fmt.Errorf("PDF 2.0 documents cannot conform to PDF/A-2")
+	}
+
+	root, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+
+	profile, err := ctx.NewStreamDictForBuf(srgbProfile)
+	if err != nil {
+		return err
+	}
+	profile.InsertInt("N", 3)
+	if err := profile.Encode(); err != nil {
+		return err
+	}
+	profileRef, err := ctx.IndRefForNewObject(*profile)
+	if err != nil {
+		return err
+	}
+	root["OutputIntents"] = types.Array{types.Dict{
+		"Type":                      types.Name("OutputIntent"),
+		"S":                         types.Name("GTS_PDFA1"),
+		"OutputConditionIdentifier": types.StringLiteral(srgbOutputCondition),
+		"Info":                      types.StringLiteral(srgbOutputCondition),
+		"DestOutputProfile":         *profileRef,
+	}}
+
+	fixed := 0
+	err = forEachAnnotation(ctx, func(annot types.Dict) {
+		if subtype := annot.NameEntry("Subtype"); subtype != nil && *subtype == "Popup" {
+			return
+		}
+		flags := 0
+		if f := annot.IntEntry("F"); f != nil {
+			flags = *f
+		}
+		want := flags&^int(model.AnnInvisible|model.AnnHidden|model.AnnNoView|model.AnnToggleNoView) | int(model.AnnPrint)
+		if want != flags || annot.IntEntry("F") == nil {
+			annot["F"] = types.Integer(want)
+			fixed++
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read annotations: %w", err)
+	}
+
+	if err := api.WriteContext(ctx, w); err != nil {
+		return fmt.Errorf("failed to write PDF/A document: %w", err)
+	}
+
+	slog.Info("prepared PDF/A-2b document", "output_intent", srgbOutputCondition, "annotations_fixed", fixed)
+	return nil
+}
+
+// CheckPDFA verifies the PDF read from rs against the main requirements of
+// PDF/A-2b and reports the outcome of every check. It is not a full
+// conformance validator, but catches what commonly breaks archival. syntax
+// holds the problems ValidatePDF already found in the same document, which
+// make up the CheckSyntax outcome.
+func CheckPDFA(rs io.ReadSeeker, syntax []string) ([]ConformanceCheck, error) {
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+
+	ctx, err := readPDF(rs, conf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PDF: %w", err)
+	}
+	root, err := ctx.Catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	var checks []ConformanceCheck
+	check := func(name string, problem string) {
+		checks = append(checks, ConformanceCheck{Name: name, Passed: problem == "", Detail: problem})
+	}
+
+	version := ctx.HeaderVersion
+	if ctx.RootVersion != nil {
+		version = ctx.RootVersion
+	}
+	if *version == model.V20 {
+		check(CheckVersion, "PDF 2.0 is not allowed, PDF/A-2 is based on PDF 1.7")
+	} else {
+		check(CheckVersion, "")
+	}
+
+	if ctx.Encrypt != nil {
+		check(CheckEncryption, "the document is encrypted")
+	} else {
+		check(CheckEncryption, "")
+	}
+
+	check(CheckOutputIntent, outputIntentProblem(ctx, root))
+	check(CheckXMPMetadata, xmpProblem(ctx, root))
+
+	// Fonts are collected while optimizing, which needs a context of its
+	// own as it rewrites the object graph.
+	fontProblem, err := fontsProblem(rs)
+	if err != nil {
+		return nil, err
+	}
+	check(CheckFonts, fontProblem)
+
+	var hidden []string
+	err = forEachAnnotation(ctx, func(annot types.Dict) {
+		subtype := "Annot"
+		if s := annot.NameEntry("Subtype"); s != nil {
+			subtype = *s
+		}
+		if subtype == "Popup" {
+			return
+		}
+		f := annot.IntEntry("F")
+		if f == nil || *f&int(model.AnnPrint) == 0 || *f&int(model.AnnInvisible|model.AnnHidden|model.AnnNoView|model.AnnToggleNoView) != 0 {
+			hidden = append(hidden, subtype)
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read annotations: %w", err)
+	}
+	if len(hidden) > 0 {
+		check(CheckAnnotations, fmt.Sprintf("%d annotations are not printable or are hidden", len(hidden)))
+	} else {
+		check(CheckAnnotations, "")
+	}
+
+	check(CheckTransparency, transparencyProblem(ctx))
+	check(CheckJavaScript, javaScriptProblem(ctx, root))
+
+	if attachments, err := ctx.ListAttachments(); err == nil && len(attachments) > 0 {
+		check(CheckEmbeddedFiles, fmt.Sprintf("%d embedded files, which PDF/A-2 only allows when they conform to PDF/A themselves", len(attachments)))
+	} else {
+		check(CheckEmbeddedFiles, "")
+	}
+
+	check(CheckSyntax, strings.Join(syntax, "; "))
+
+	return checks, nil
+}
+
+// ValidatePDF checks the PDF read from rs against the PDF specification and
+// returns the problems found. pdfcpu stops at the first one. password opens
+// encrypted documents.
+func ValidatePDF(rs io.ReadSeeker, password string) []string {
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.VALIDATE
+	// In strict mode pdfcpu only decrypts AES-256 (V5) in PDF 2.0, while
+	// Encrypt writes it into PDF 1.7 files as Adobe Extension Level 3 does.
+	// The document is read relaxed and then validated strictly.
+	conf.ValidationMode = model.ValidationRelaxed
+	conf.UserPW, conf.OwnerPW = password, password
+
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return []string{err.Error()}
+	}
+	ctx, err := api.ReadContext(rs, conf)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	ctx.XRefTable.ValidationMode = model.ValidationStrict
+	if err := api.ValidateContext(ctx); err != nil {
+		return []string{err.Error()}
+	}
+	return nil
+}
+
+// forEachAnnotation calls fn with every annotation of every page of ctx.
+func forEachAnnotation(ctx *model.Context, fn func(annot types.Dict)) error {
+	for p := 1; p <= ctx.PageCount; p++ {
+		d, _, _, err := ctx.PageDict(p, false)
+		if err != nil {
+			return err
+		}
+		annots, err := ctx.DereferenceArray(d["Annots"])
+		if err != nil || annots == nil {
+			continue
+		}
+		for _, obj := range annots {
+			if annot, err := ctx.DereferenceDict(obj); err == nil && annot != nil {
+				fn(annot)
+			}
+		}
+	}
+	return nil
+}
+
+// outputIntentProblem describes what is wrong with the PDF/A output intent
+// of the catalog, or returns "".
+func outputIntentProblem(ctx *model.Context, root types.Dict) string {
+	intents, err := ctx.DereferenceArray(root["OutputIntents"])
+	if err != nil || len(intents) == 0 {
+		return "no output intent"
+	}
+	for _, obj := range intents {
+		intent, err := ctx.DereferenceDict(obj)
+		if err != nil || intent == nil {
+			continue
+		}
+		if s := intent.NameEntry("S"); s == nil || *s != "GTS_PDFA1" {
+			continue
+		}
+		if _, ok := intent["DestOutputProfile"]; !ok {
+			return "the PDF/A output intent has no ICC profile"
+		}
+		return ""
+	}
+	return "no GTS_PDFA1 output intent"
+}
+
+// pdfaPartPattern and pdfaConformancePattern match the PDF/A-2b
+// identification of an XMP packet, as elements or attributes.
+var (
+	pdfaPartPattern        = regexp.MustCompile(`pdfaid:part(?:>|=["'])\s*2\b`)
+	pdfaConformancePattern = regexp.MustCompile(`pdfaid:conformance(?:>|=["'])\s*[Bb]\b`)
+)
+
+// xmpProblem describes what is wrong with the XMP metadata of the catalog,
+// or returns "".
+func xmpProblem(ctx *model.Context, root types.Dict) string {
+	ref, ok := root["Metadata"].(types.IndirectRef)
+	if !ok {
+		return "no XMP metadata stream"
+	}
+	sd, _, err := ctx.DereferenceStreamDict(ref)
+	if err != nil || sd == nil {
+		return "no XMP metadata stream"
+	}
+	if _, ok := sd.Find("Filter"); ok {
+		return "the XMP metadata stream is compressed"
+	}
+	if err := sd.Decode(); err != nil {
+		return fmt.Sprintf("unreadable XMP metadata: %v", err)
+	}
+	if !pdfaPartPattern.Match(sd.Content) || !pdfaConformancePattern.Match(sd.Content) {
+		return "the XMP metadata does not declare PDF/A-2b"
+	}
+	return ""
+}
+
+// fontsProblem lists the fonts of the PDF read from rs that are not
+// embedded, or returns "".
+func fontsProblem(rs io.ReadSeeker) (string, error) {
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+
+	ctx, err := readPDF(rs, conf)
+	if err != nil {
+		return "", fmt.Errorf("failed to read PDF: %w", err)
+	}
+	if err := api.OptimizeContext(ctx); err != nil {
+		return "", fmt.Errorf("failed to read fonts: %w", err)
+	}
+	info, err := pdfcpu.Info(ctx, "", nil, true)
+	if err != nil {
+		return "", fmt.Errorf("failed to read fonts: %w", err)
+	}
+
+	var missing []string
+	for _, f := range info.Fonts {
+		// Type 3 glyphs are drawn by content streams inside the document.
+		if !f.Embedded && f.Type != "Type3" && !slices.Contains(missing, f.Name) {
+			missing = append(missing, f.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return "fonts not embedded: " + strings.Join(missing, ", "), nil
+	}
+	return "", nil
+}
+
+// standardBlendModes are the blend modes of PDF 1.7, the only ones PDF/A-2
+// allows.
+var standardBlendModes = []string{
+	"Normal", "Compatible", "Multiply", "Screen", "Overlay", "Darken", "Lighten",
+	"ColorDodge", "ColorBurn", "HardLight", "SoftLight", "Difference", "Exclusion",
+	"Hue", "Saturation", "Color", "Luminosity",
+}
+
+// transparencyProblem describes transparency PDF/A-2 forbids, such as
+// non-standard blend modes, or returns "". Transparency groups need an
+// output intent when they have no color space, which is checked separately.
+func transparencyProblem(ctx *model.Context) string {
+	seen := map[int]bool{}
+	var modes []string
+
+	var walk func(res types.Dict)
+	walk = func(res types.Dict) {
+		if gs, err := ctx.DereferenceDict(res["ExtGState"]); err == nil {
+			for _, obj := range gs {
+				state, err := ctx.DereferenceDict(obj)
+				if err != nil || state == nil {
+					continue
+				}
+				for _, bm := range blendModes(ctx, state["BM"]) {
+					if !slices.Contains(standardBlendModes, bm) && !slices.Contains(modes, bm) {
+						modes = append(modes, bm)
+					}
+				}
+			}
+		}
+		xobjects, err := ctx.DereferenceDict(res["XObject"])
+		if err != nil {
+			return
+		}
+		for _, obj := range xobjects {
+			ref, ok := obj.(types.IndirectRef)
+			if !ok || seen[ref.ObjectNumber.Value()] {
+				continue
+			}
+			seen[ref.ObjectNumber.Value()] = true
+			sd, _, err := ctx.DereferenceStreamDict(ref)
+			if err != nil || sd == nil {
+				continue
+			}
+			if sub, err := ctx.DereferenceDict(sd.Dict["Resources"]); err == nil && sub != nil {
+				walk(sub)
+			}
+		}
+	}
+
+	for p := 1; p <= ctx.PageCount; p++ {
+		_, _, inh, err := ctx.PageDict(p, false)
+		if err != nil || inh == nil || inh.Resources == nil {
+			continue
+		}
+		walk(inh.Resources)
+	}
+
+	if len(modes) > 0 {
+		return "non-standard blend modes: " + strings.Join(modes, ", ")
+	}
+	return ""
+}
+
+// blendModes returns the names of a BM entry, a name or an array of names.
+func blendModes(ctx *model.Context, obj types.Object) []string {
+	obj, err := ctx.Dereference(obj)
+	if err != nil {
+		return nil
+	}
+	switch bm := obj.(type) {
+	case types.Name:
+		return []string{bm.Value()}
+	case types.Array:
+		var names []string
+		for _, o := range bm {
+			if n, ok := o.(types.Name); ok {
+				names = append(names, n.Value())
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// javaScriptProblem describes the JavaScript of the document, which PDF/A
+// forbids, or returns "".
+func javaScriptProblem(ctx *model.Context, root types.Dict) string {
+	if names, err := ctx.DereferenceDict(root["Names"]); err == nil && names != nil {
+		if _, ok := names["JavaScript"]; ok {
+			return "the document has JavaScript"
+		}
+	}
+	isJS := func(obj types.Object) bool {
+		action, err := ctx.DereferenceDict(obj)
+		if err != nil || action == nil {
+			return false
+		}
+		s := action.NameEntry("S")
+		return s != nil && *s == "JavaScript"
+	}
+	if isJS(root["OpenAction"]) {
+		return "the document runs JavaScript when opened"
+	}
+
+	scripted := 0
+	forEachAnnotation(ctx, func(annot types.Dict) {
+		if isJS(annot["A"]) {
+			scripted++
+		}
+	})
+	if scripted > 0 {
+		return fmt.Sprintf("%d annotations run JavaScript", scripted)
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package merger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// grayscaleState names the graphics state added to every page by Grayscale.
+const grayscaleState = "RapidGrayscale"
+
+// PrintOptions adjusts a document for printing on paper.
+type PrintOptions struct {
+	// Grayscale renders every page in shades of gray, images included.
+	Grayscale bool
+	// StripLinks removes link annotations, which do nothing on paper.
+	StripLinks bool
+}
+
+// IsZero reports whether o does not change anything.
+func (o PrintOptions) IsZero() bool {
+	return !o.Grayscale && !o.StripLinks
+}
+
+// PreparePrint applies opts to the PDF read from rs and writes the result to
+// w.
+//
+// Grayscale covers every page with a neutral gray in the Saturation blend
+// mode, which keeps the lightness of whatever is below and drops its color.
+// Text and vector graphics therefore stay sharp, and uploaded PDFs are
+// converted just like rendered pages. Annotations are drawn on top of the
+// page and keep their colors.
+func PreparePrint(rs io.ReadSeeker, w io.Writer, opts PrintOptions) error {
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+
+	ctx, err := readPDF(rs, conf)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	links := 0
+	for p := 1; p <= ctx.PageCount; p++ {
+		if opts.StripLinks {
+			n, err := stripLinks(ctx, p)
+			if err != nil {
+				return fmt.Errorf("failed to strip links on page %d: %w", p, err)
+			}
+			links += n
+		}
+		if opts.Grayscale {
+			if err := grayscalePage(ctx, p); err != nil {
+				return fmt.Errorf("failed to convert page %d to grayscale: %w", p, err)
+			}
+		}
+	}
+
+	if err := api.WriteContext(ctx, w); err != nil {
+		return fmt.Errorf("failed to write print-ready PDF: %w", err)
+	}
+
+	slog.Info("prepared document for print", "grayscale", opts.Grayscale, "links_removed", links)
+	return nil
+}
+
+// stripLinks removes the link annotations of a page and returns how many
+// were removed.
+func stripLinks(ctx *model.Context, page int) (int, error) {
+	d, _, _, err := ctx.PageDict(page, false)
+	if err != nil {
+		return 0, err
+	}
+	annots, err := ctx.DereferenceArray(d["Annots"])
+	if err != nil || annots == nil {
+		return 0, nil
+	}
+
+	var kept types.Array
+	for _, obj := range annots {
+		annot, err := ctx.DereferenceDict(obj)
+		if err == nil && annot != nil && annot.Subtype() != nil && *annot.Subtype() == "Link" {
+			continue
+		}
+		kept = append(kept, obj)
+	}
+
+	removed := len(annots) - len(kept)
+	if len(kept) > 0 {
+		d["Annots"] = kept
+	} else {
+		d.Delete("Annots")
+	}
+	return removed, nil
+}
+
+// grayscalePage draws a gray rectangle over the media box of a page in the
+// Saturation blend mode. The result takes the saturation of the gray, none,
+// and the hue and luminosity of the page below (PDF 32000-1, 11.3.5.3).
+func grayscalePage(ctx *model.Context, page int) error {
+	d, _, inh, err := ctx.PageDict(page, false)
+	if err != nil {
+		return err
+	}
+	if d == nil || inh == nil || inh.MediaBox == nil {
+		return fmt.Errorf("page not found")
+	}
+
+	// The page may inherit its resources from the page tree, so they are
+	// copied onto the page before the graphics state is added.
+	res := types.Dict{}
+	if inh.Resources != nil {
+		res = inh.Resources.Clone().(types.Dict)
+	}
+	gs, err := ctx.DereferenceDict(res["ExtGState"])
+	if err != nil {
+		return err
+	}
+	if gs == nil {
+		gs = types.Dict{}
+	} else {
+		gs = gs.Clone().(types.Dict)
+	}
+	gs[grayscaleState] = types.Dict{
+		"Type": types.Name("ExtGState"),
+		"BM":   types.Name("Saturation"),
+	}
+	res["ExtGState"] = gs
+	d["Resources"] = res
+
+	// Wrap the existing content so state it leaves behind, such as a
+	// clipping path, cannot affect the overlay.
+	box := inh.MediaBox
+	overlay := fmt.Sprintf("\nQ\nq /%s gs 0.5 g %.5f %.5f %.5f %.5f re f Q\n",
+		grayscaleState, box.LL.X, box.LL.Y, box.Width(), box.Height())
+	if err := ctx.AppendContent(d, []byte(overlay)); err != nil {
+		return err
+	}
+	return prependContent(ctx, d, []byte("q\n"))
+}
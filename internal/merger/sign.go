@@ -0,0 +1,684 @@
+package merger
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/digitorus/pkcs7"
+	"github.com/digitorus/timestamp"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+const (
+	// byteRangePlaceholder reserves room for the final /ByteRange, which is
+	// only known once the signed revision has been written.
+	byteRangePlaceholder = "/ByteRange[0 9999999999 9999999999 9999999999]"
+	// signatureReserve is the room kept for the CMS signature on top of the
+	// certificates it embeds.
+	signatureReserve = 8192
+	// timestampReserve is the extra room kept for an RFC 3161 token, which
+	// embeds the TSA certificates.
+	timestampReserve = 16384
+	// tsaTimeout bounds a request to the timestamp authority.
+	tsaTimeout = 30 * time.Second
+	// maxTSAResponse caps the size of a timestamp response.
+	maxTSAResponse = 1 << 20
+)
+
+var (
+	// oidSigningCertificateV2 is the ESS signing-certificate-v2 attribute
+	// required by PAdES to bind the signer certificate to the signature.
+	oidSigningCertificateV2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 47}
+	// oidTimeStampToken is the unsigned attribute holding an RFC 3161 token
+	// over the signature value.
+	oidTimeStampToken = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 14}
+)
+
+// Signer holds the certificate and private key documents are signed with.
+type Signer struct {
+	Certificate *x509.Certificate
+	// Chain holds the intermediate certificates embedded in signatures.
+	Chain []*x509.Certificate
+	Key   crypto.Signer
+	// TSAURL is an RFC 3161 timestamp authority. Empty disables timestamping.
+	TSAURL string
+}
+
+// LoadSigner reads a signing certificate and its private key. certFile is
+// either a PKCS#12 bundle (.p12 or .pfx) protected by password, or a PEM file
+// with the certificate followed by its chain. The PEM private key is read from
+// keyFile, or from certFile when keyFile is empty, and must not be encrypted.
+func LoadSigner(certFile, keyFile, password, tsaURL string) (*Signer, error) {
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+
+	var signer *Signer
+	if isPKCS12(certFile, data) {
+		signer, err = loadPKCS12(data, password)
+	} else {
+		signer, err = loadPEM(data, keyFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	type publicKey interface{ Equal(crypto.PublicKey) bool }
+	pub, ok := signer.Key.Public().(publicKey)
+	if !ok || !pub.Equal(signer.Certificate.PublicKey) {
+		return nil, fmt.Errorf("private key does not match the certificate")
+	}
+
+	signer.TSAURL = tsaURL
+	return signer, nil
+}
+
+// isPKCS12 reports whether the certificate file is a PKCS#12 bundle rather
+// than PEM.
+func isPKCS12(name string, data []byte) bool {
+	switch strings.ToLower(name[strings.LastIndex(name, ".")+1:]) {
+	case "p12", "pfx":
+		return true
+	}
+	return !bytes.Contains(data, []byte("-----BEGIN"))
+}
+
+// loadPKCS12 decodes a PKCS#12 bundle.
+func loadPKCS12(data []byte, password string) (*Signer, error) {
+	key, cert, chain, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode PKCS#12 bundle: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return &Signer{Certificate: cert, Chain: chain, Key: signer}, nil
+}
+
+// loadPEM decodes the certificates of data and the private key of keyFile,
+// or of data itself when keyFile is empty.
+func loadPEM(data []byte, keyFile string) (*Signer, error) {
+	keyData := data
+	if keyFile != "" {
+		var err error
+		if keyData, err = os.ReadFile(keyFile); err != nil {
+			return nil, fmt.Errorf("failed to read private key: %w", err)
+		}
+	}
+
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate found")
+	}
+
+	for block, rest := pem.Decode(keyData); block != nil; block, rest = pem.Decode(rest) {
+		var key any
+		var err error
+		switch block.Type {
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+		case "ENCRYPTED PRIVATE KEY":
+			return nil, fmt.Errorf("encrypted PEM private keys are not supported, use a PKCS#12 bundle")
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return &Signer{Certificate: certs[0], Chain: certs[1:], Key: signer}, nil
+	}
+
+	return nil, fmt.Errorf("no private key found")
+}
+
+// Signature describes the signature applied to a document.
+type Signature struct {
+	Reason      string
+	Location    string
+	ContactInfo string
+
+	// Visible draws a box with the signer, date, reason and location on
+	// the page. Otherwise the signature has no appearance.
+	Visible bool
+	// Page holds the visible signature, starting at 1. Zero selects the
+	// last page.
+	Page int
+	// Rect is the box of a visible signature as lower-left x, lower-left y,
+	// upper-right x and upper-right y in points. The zero value places it in
+	// the bottom right corner.
+	Rect [4]float64
+}
+
+// defaultSignatureRect is the visible signature box used when none is set,
+// in the bottom right corner of an A4 page.
+var defaultSignatureRect = [4]float64{355, 36, 559, 96}
+
+// Validate checks that the signature box is well-formed.
+func (s Signature) Validate() error {
+	if s.Page < 0 {
+		return fmt.Errorf("invalid signature page %d", s.Page)
+	}
+	if s.Rect != [4]float64{} && (s.Rect[2] <= s.Rect[0] || s.Rect[3] <= s.Rect[1]) {
+		return fmt.Errorf("invalid signature rectangle %v", s.Rect)
+	}
+	return nil
+}
+
+// Sign applies a PAdES-B signature to the PDF in rws as an incremental
+// update, so it must run after every other change to the document. The
+// signature is timestamped when the signer has a TSA URL. Encrypted documents
+// cannot be signed, as their signature would be encrypted too.
+func Sign(ctx context.Context, rws io.ReadWriteSeeker, signer *Signer, sig Signature) error {
+	if err := sig.Validate(); err != nil {
+		return err
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+	// Validating before the write would move new direct objects, such as
+	// the form fields, into indirect objects missing from the update.
+	conf.PostProcessValidate = false
+
+	pdf, err := readPDF(rws, conf)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+	if pdf.Encrypt != nil {
+		return fmt.Errorf("encrypted documents cannot be signed")
+	}
+
+	pdf.Write.Increment = true
+	pdf.Write.Offset = pdf.Read.FileSize
+
+	reserve := signer.signatureSize()
+	if err := addSignatureField(pdf, signer, sig, reserve); err != nil {
+		return fmt.Errorf("failed to add signature field: %w", err)
+	}
+
+	if err := api.WriteIncr(pdf, rws, conf); err != nil {
+		return fmt.Errorf("failed to write signature field: %w", err)
+	}
+
+	if err := signer.fillSignature(ctx, rws, reserve); err != nil {
+		return err
+	}
+
+	slog.Info("PDF signed", "signer", signer.Certificate.Subject.CommonName,
+		"visible", sig.Visible, "timestamped", signer.TSAURL != "")
+	return nil
+}
+
+// signatureSize returns the number of bytes reserved for the CMS signature.
+func (s *Signer) signatureSize() int {
+	size := signatureReserve + len(s.Certificate.Raw)
+	for _, cert := range s.Chain {
+		size += len(cert.Raw)
+	}
+	if s.TSAURL != "" {
+		size += timestampReserve
+	}
+	return size
+}
+
+// addSignatureField adds a signature field whose value holds the
+// placeholders filled in by fillSignature.
+func addSignatureField(ctx *model.Context, signer *Signer, sig Signature, reserve int) error {
+	now := time.Now()
+
+	v := types.Dict{
+		"Type":      types.Name("Sig"),
+		"Filter":    types.Name("Adobe.PPKLite"),
+		"SubFilter": types.Name("ETSI.CAdES.detached"),
+		"ByteRange": types.NewIntegerArray(0, 9999999999, 9999999999, 9999999999),
+		"Contents":  types.HexLiteral(strings.Repeat("0", reserve*2)),
+		"M":         types.StringLiteral(types.DateString(now)),
+	}
+	entries := map[string]string{
+		"Name":        signer.Certificate.Subject.CommonName,
+		"Reason":      sig.Reason,
+		"Location":    sig.Location,
+		"ContactInfo": sig.ContactInfo,
+	}
+	for key, value := range entries {
+		if value == "" {
+			continue
+		}
+		s, err := pdfText(value)
+		if err != nil {
+			return err
+		}
+		v[key] = s
+	}
+
+	vRef, err := ctx.IndRefForNewObject(v)
+	if err != nil {
+		return err
+	}
+	ctx.Write.IncrementWithObjNr(vRef.ObjectNumber.Value())
+
+	pageNr := sig.Page
+	if pageNr == 0 {
+		pageNr = ctx.PageCount
+	}
+	if pageNr > ctx.PageCount {
+		return fmt.Errorf("signature page %d is beyond the last page %d", pageNr, ctx.PageCount)
+	}
+	page, pageRef, _, err := ctx.PageDict(pageNr, false)
+	if err != nil || page == nil {
+		return fmt.Errorf("invalid page %d: %w", pageNr, err)
+	}
+
+	root, err := ctx.Catalog()
+	if err != nil {
+		return err
+	}
+	form, formRef, err := acroForm(ctx, root)
+	if err != nil {
+		return err
+	}
+	fields, _ := form.Find("Fields")
+	fieldCount := 0
+	if arr, err := ctx.DereferenceArray(fields); err == nil {
+		fieldCount = len(arr)
+	}
+
+	widget := types.Dict{
+		"Type":    types.Name("Annot"),
+		"Subtype": types.Name("Widget"),
+		"FT":      types.Name("Sig"),
+		"T":       types.StringLiteral("Signature" + strconv.Itoa(fieldCount+1)),
+		"V":       *vRef,
+		// Print and Locked.
+		"F":    types.Integer(132),
+		"P":    *pageRef,
+		"Rect": types.NewNumberArray(0, 0, 0, 0),
+	}
+	if sig.Visible {
+		rect := sig.Rect
+		if rect == [4]float64{} {
+			rect = defaultSignatureRect
+		}
+		ap, err := signatureAppearance(ctx, signer, sig, rect, now)
+		if err != nil {
+			return err
+		}
+		widget["Rect"] = types.NewNumberArray(rect[:]...)
+		widget["AP"] = types.Dict{"N": *ap}
+	}
+
+	widgetRef, err := ctx.IndRefForNewObject(widget)
+	if err != nil {
+		return err
+	}
+	ctx.Write.IncrementWithObjNr(widgetRef.ObjectNumber.Value())
+
+	if err := appendToArray(ctx, page, "Annots", *widgetRef); err != nil {
+		return err
+	}
+	ctx.Write.IncrementWithObjNr(pageRef.ObjectNumber.Value())
+
+	if err := appendToArray(ctx, form, "Fields", *widgetRef); err != nil {
+		return err
+	}
+	// SignaturesExist and AppendOnly.
+	form["SigFlags"] = types.Integer(3)
+	if formRef != nil {
+		ctx.Write.IncrementWithObjNr(formRef.ObjectNumber.Value())
+	}
+	ctx.Write.IncrementWithObjNr(ctx.Root.ObjectNumber.Value())
+
+	return nil
+}
+
+// acroForm returns the interactive form of the document, creating it when the
+// document has none. The reference is nil when the form is stored directly in
+// the catalog.
+func acroForm(ctx *model.Context, root types.Dict) (types.Dict, *types.IndirectRef, error) {
+	o, ok := root.Find("AcroForm")
+	if !ok {
+		form := types.Dict{"Fields": types.Array{}}
+		root["AcroForm"] = form
+		return form, nil, nil
+	}
+
+	form, err := ctx.DereferenceDict(o)
+	if err != nil || form == nil {
+		return nil, nil, fmt.Errorf("invalid AcroForm: %w", err)
+	}
+	if ir, ok := o.(types.IndirectRef); ok {
+		return form, &ir, nil
+	}
+	return form, nil, nil
+}
+
+// appendToArray appends ir to the array stored under key in d, following an
+// indirect array and scheduling it for the incremental update.
+func appendToArray(ctx *model.Context, d types.Dict, key string, ir types.IndirectRef) error {
+	o, ok := d.Find(key)
+	if !ok {
+		d[key] = types.Array{ir}
+		return nil
+	}
+
+	arr, err := ctx.DereferenceArray(o)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+	arr = append(arr, ir)
+
+	ref, ok := o.(types.IndirectRef)
+	if !ok {
+		d[key] = arr
+		return nil
+	}
+	entry, found := ctx.FindTableEntryForIndRef(&ref)
+	if !found {
+		return fmt.Errorf("invalid %s reference", key)
+	}
+	entry.Object = arr
+	ctx.Write.IncrementWithObjNr(ref.ObjectNumber.Value())
+	return nil
+}
+
+// signatureAppearance creates the form XObject drawn for a visible signature:
+// a framed box listing the signer, the signing date, the reason and the
+// location.
+func signatureAppearance(ctx *model.Context, signer *Signer, sig Signature, rect [4]float64, now time.Time) (*types.IndirectRef, error) {
+	w, h := rect[2]-rect[0], rect[3]-rect[1]
+
+	lines := []string{
+		"Digitally signed by " + signer.Certificate.Subject.CommonName,
+		"Date: " + now.Format("2006-01-02 15:04:05 -07:00"),
+	}
+	if sig.Reason != "" {
+		lines = append(lines, "Reason: "+sig.Reason)
+	}
+	if sig.Location != "" {
+		lines = append(lines, "Location: "+sig.Location)
+	}
+
+	const padding = 4
+	fontSize := min(10, (h-2*padding)/(float64(len(lines))*1.2))
+	leading := fontSize * 1.2
+
+	// Helvetica uses WinAnsiEncoding, so text is converted to Windows-1252
+	// with unsupported characters replaced.
+	encoder := encoding.ReplaceUnsupported(charmap.Windows1252.NewEncoder())
+
+	float := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', 2, 64)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "q 0 0 %s %s re W n\n", float(w), float(h))
+	fmt.Fprintf(&b, "0.5 w 0.2 0.2 0.2 RG 0.25 0.25 %s %s re S\n", float(w-0.5), float(h-0.5))
+	fmt.Fprintf(&b, "BT /F1 %s Tf %s TL %d %s Td\n", float(fontSize), float(leading), padding, float(h-padding-fontSize))
+	for i, line := range lines {
+		encoded, err := encoder.String(line)
+		if err != nil {
+			return nil, err
+		}
+		escaped, err := types.Escape(encoded)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			b.WriteString("T* ")
+		}
+		fmt.Fprintf(&b, "(%s) Tj\n", *escaped)
+	}
+	b.WriteString("ET Q\n")
+
+	font := types.Dict{
+		"Type":     types.Name("Font"),
+		"Subtype":  types.Name("Type1"),
+		"BaseFont": types.Name("Helvetica"),
+		"Encoding": types.Name("WinAnsiEncoding"),
+	}
+
+	sd := types.StreamDict{Dict: types.NewDict(), Content: []byte(b.String())}
+	sd.InsertName("Type", "XObject")
+	sd.InsertName("Subtype", "Form")
+	sd.Insert("BBox", types.NewNumberArray(0, 0, w, h))
+	sd.Insert("Resources", types.Dict{"Font": types.Dict{"F1": font}})
+	if err := sd.Encode(); err != nil {
+		return nil, err
+	}
+
+	ir, err := ctx.IndRefForNewObject(sd)
+	if err != nil {
+		return nil, err
+	}
+	ctx.Write.IncrementWithObjNr(ir.ObjectNumber.Value())
+	return ir, nil
+}
+
+// fillSignature replaces the placeholders of the revision just written to f
+// with the byte range it covers and the CMS signature over those bytes.
+func (s *Signer) fillSignature(ctx context.Context, rws io.ReadWriteSeeker, reserve int) error {
+	if _, err := rws.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(rws)
+	if err != nil {
+		return fmt.Errorf("failed to read signed revision: %w", err)
+	}
+
+	// The placeholders are found by their serialization, so a change in how
+	// pdfcpu writes them must fail here rather than sign the wrong bytes.
+	if n := bytes.Count(data, []byte(byteRangePlaceholder)); n != 1 {
+		return fmt.Errorf("expected one signature byte range placeholder, found %d", n)
+	}
+	byteRangeAt := bytes.Index(data, []byte(byteRangePlaceholder))
+	placeholder := []byte("/Contents<" + strings.Repeat("0", reserve*2) + ">")
+	if n := bytes.Count(data[byteRangeAt:], placeholder); n != 1 {
+		return fmt.Errorf("expected one signature contents placeholder after the byte range, found %d", n)
+	}
+	contentsAt := bytes.Index(data[byteRangeAt:], placeholder)
+
+	// The signed bytes are everything except the hex string of /Contents,
+	// including its delimiters.
+	start := byteRangeAt + contentsAt + len("/Contents")
+	end := start + reserve*2 + 2
+
+	byteRange := fmt.Sprintf("/ByteRange[0 %d %d %d]", start, end, len(data)-end)
+	byteRange += strings.Repeat(" ", len(byteRangePlaceholder)-len(byteRange))
+	copy(data[byteRangeAt:], byteRange)
+
+	signed := make([]byte, 0, len(data)-(end-start))
+	signed = append(signed, data[:start]...)
+	signed = append(signed, data[end:]...)
+
+	cms, err := s.signCMS(ctx, signed)
+	if err != nil {
+		return err
+	}
+	if len(cms) > reserve {
+		return fmt.Errorf("signature of %d bytes exceeds the %d bytes reserved", len(cms), reserve)
+	}
+	hex.Encode(data[start+1:], cms)
+
+	if _, err := rws.Seek(int64(byteRangeAt), io.SeekStart); err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+	if _, err := rws.Write(data[byteRangeAt:end]); err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+	return nil
+}
+
+// essCertIDv2 identifies the signer certificate by its SHA-256 hash, the
+// default algorithm, which is therefore omitted.
+type essCertIDv2 struct {
+	CertHash []byte
+}
+
+type signingCertificateV2 struct {
+	Certs []essCertIDv2
+}
+
+// signCMS creates a detached CAdES signature over data, timestamping the
+// signature value when a TSA is configured.
+func (s *Signer) signCMS(ctx context.Context, data []byte) ([]byte, error) {
+	sd, err := pkcs7.NewSignedData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signature: %w", err)
+	}
+	sd.SetDigestAlgorithm(pkcs7.OIDDigestAlgorithmSHA256)
+
+	certHash := sha256.Sum256(s.Certificate.Raw)
+	config := pkcs7.SignerInfoConfig{
+		ExtraSignedAttributes: []pkcs7.Attribute{{
+			Type:  oidSigningCertificateV2,
+			Value: signingCertificateV2{Certs: []essCertIDv2{{CertHash: certHash[:]}}},
+		}},
+	}
+	if err := sd.AddSignerChain(s.Certificate, s.Key, s.Chain, config); err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+
+	// AddSignerChain always adds a signing-time attribute, which a PAdES
+	// baseline signature must not contain (ETSI EN 319 142-1, 5.2.1): the
+	// signing time is the /M entry of the signature dictionary instead. The
+	// attribute is removed and the remaining ones are signed again.
+	signerInfo := &sd.GetSignedData().SignerInfos[0]
+	attrs := signerInfo.AuthenticatedAttributes
+	for i := range attrs {
+		if attrs[i].Type.Equal(pkcs7.OIDAttributeSigningTime) {
+			attrs = append(attrs[:i], attrs[i+1:]...)
+			break
+		}
+	}
+	// The attributes are already in DER order, which removing one keeps.
+	var encoded []byte
+	for _, attr := range attrs {
+		b, err := asn1.Marshal(attr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode signed attributes: %w", err)
+		}
+		encoded = append(encoded, b...)
+	}
+	signature, err := s.signAttributes(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	signerInfo.AuthenticatedAttributes = attrs
+	signerInfo.EncryptedDigest = signature
+
+	if s.TSAURL != "" {
+		token, err := s.timestamp(ctx, signerInfo.EncryptedDigest)
+		if err != nil {
+			return nil, err
+		}
+		if err := signerInfo.SetUnauthenticatedAttributes([]pkcs7.Attribute{{
+			Type:  oidTimeStampToken,
+			Value: asn1.RawValue{FullBytes: token},
+		}}); err != nil {
+			return nil, fmt.Errorf("failed to add timestamp: %w", err)
+		}
+	}
+
+	sd.Detach()
+	cms, err := sd.Finish()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signature: %w", err)
+	}
+	return cms, nil
+}
+
+// signAttributes signs the DER encoding of a SET OF signed attributes, given
+// the concatenated encoding of its elements.
+func (s *Signer) signAttributes(elements []byte) ([]byte, error) {
+	set, err := asn1.Marshal(asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: elements})
+	if err != nil {
+		return nil, err
+	}
+	// Ed25519 hashes the message itself.
+	if _, ok := s.Key.Public().(ed25519.PublicKey); ok {
+		return s.Key.Sign(rand.Reader, set, crypto.Hash(0))
+	}
+	digest := sha256.Sum256(set)
+	return s.Key.Sign(rand.Reader, digest[:], crypto.SHA256)
+}
+
+// timestamp requests an RFC 3161 timestamp token over signature from the TSA.
+func (s *Signer) timestamp(ctx context.Context, signature []byte) ([]byte, error) {
+	query, err := timestamp.CreateRequest(bytes.NewReader(signature), &timestamp.RequestOptions{
+		Hash:         crypto.SHA256,
+		Certificates: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create timestamp request: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, tsaTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.TSAURL, bytes.NewReader(query))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TSA URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/timestamp-query")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("timestamp request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("timestamp request failed: TSA returned %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTSAResponse))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read timestamp response: %w", err)
+	}
+
+	ts, err := timestamp.ParseResponse(body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp response: %w", err)
+	}
+	return ts.RawToken, nil
+}
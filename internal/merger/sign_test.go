@@ -0,0 +1,198 @@
+package merger
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/digitorus/pkcs7"
+)
+
+// testSigner returns a signer with a self-signed certificate for key.
+func testSigner(t *testing.T, key crypto.Signer) *Signer {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "Rapid PDF Test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Signer{Certificate: cert, Key: key}
+}
+
+// signTestPDF signs data with signer and returns the signed document.
+func signTestPDF(t *testing.T, data []byte, signer *Signer, sig Signature) []byte {
+	t.Helper()
+	f, err := os.OpenFile(writeTestPDF(t, "in.pdf", data), os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := Sign(context.Background(), f, signer, sig); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	signed, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+var byteRangePattern = regexp.MustCompile(`/ByteRange\[(\d+) (\d+) (\d+) (\d+) *\]`)
+
+// verifyLastSignature checks that the last signature of data covers the
+// whole file but its own value and that its CMS verifies over those bytes.
+func verifyLastSignature(t *testing.T, data []byte) *pkcs7.PKCS7 {
+	t.Helper()
+
+	matches := byteRangePattern.FindAllSubmatch(data, -1)
+	if len(matches) == 0 {
+		t.Fatal("no byte range found")
+	}
+	var br [4]int
+	for i := range br {
+		br[i], _ = strconv.Atoi(string(matches[len(matches)-1][i+1]))
+	}
+	if br[0] != 0 || br[2]+br[3] != len(data) || br[1] >= br[2] {
+		t.Fatalf("byte range %v does not cover the %d bytes of the file", br, len(data))
+	}
+	if data[br[1]] != '<' || data[br[2]-1] != '>' {
+		t.Fatalf("byte range %v does not exclude exactly the signature value", br)
+	}
+
+	der, err := hex.DecodeString(string(data[br[1]+1 : br[2]-1]))
+	if err != nil {
+		t.Fatalf("invalid signature value: %v", err)
+	}
+	// The value is padded with zeros up to the reserved size.
+	var cms asn1.RawValue
+	if _, err := asn1.Unmarshal(der, &cms); err != nil {
+		t.Fatalf("invalid CMS: %v", err)
+	}
+	p7, err := pkcs7.Parse(cms.FullBytes)
+	if err != nil {
+		t.Fatalf("invalid CMS: %v", err)
+	}
+
+	p7.Content = append(append([]byte{}, data[:br[1]]...), data[br[2]:]...)
+	if err := p7.Verify(); err != nil {
+		t.Fatalf("signature does not verify: %v", err)
+	}
+	return p7
+}
+
+func TestSign(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		signer *Signer
+		sig    Signature
+	}{
+		{"ecdsa invisible", testSigner(t, ecKey), Signature{Reason: "Approval"}},
+		{"rsa visible", testSigner(t, rsaKey), Signature{Visible: true, Page: 1, Location: "São Paulo"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signed := signTestPDF(t, testPDF(t, 2), tt.signer, tt.sig)
+			p7 := verifyLastSignature(t, signed)
+
+			var signingTime time.Time
+			if err := p7.UnmarshalSignedAttribute(pkcs7.OIDAttributeSigningTime, &signingTime); err == nil {
+				t.Error("CMS has a signing-time attribute, which PAdES forbids")
+			}
+			var certs signingCertificateV2
+			if err := p7.UnmarshalSignedAttribute(oidSigningCertificateV2, &certs); err != nil {
+				t.Errorf("CMS lacks the signing-certificate-v2 attribute: %v", err)
+			}
+			if !regexp.MustCompile(`/M\s*\(D:\d{14}`).Match(signed) {
+				t.Error("signature dictionary has no /M signing time")
+			}
+			if problems := ValidatePDF(bytes.NewReader(signed), ""); len(problems) > 0 {
+				t.Errorf("signed PDF is invalid: %v", problems)
+			}
+		})
+	}
+}
+
+func TestSignTwice(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := testSigner(t, key)
+
+	first := signTestPDF(t, testPDF(t, 1), signer, Signature{})
+	second := signTestPDF(t, first, signer, Signature{})
+
+	if !bytes.HasPrefix(second, first) {
+		t.Fatal("second signature rewrote the first revision")
+	}
+	verifyLastSignature(t, first)
+	verifyLastSignature(t, second)
+}
+
+func TestFillSignatureRequiresOnePlaceholder(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := testSigner(t, key)
+	contents := "/Contents<" + strings.Repeat("0", 8) + ">"
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"missing", "%PDF-1.7\n" + contents},
+		{"duplicated", "%PDF-1.7\n" + byteRangePlaceholder + contents + byteRangePlaceholder + contents},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.OpenFile(writeTestPDF(t, "in.pdf", []byte(tt.data)), os.O_RDWR, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			err = signer.fillSignature(context.Background(), f, 4)
+			if err == nil || !strings.Contains(err.Error(), "placeholder") {
+				t.Fatalf("fillSignature() error = %v, want a placeholder error", err)
+			}
+		})
+	}
+}
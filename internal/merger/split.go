@@ -0,0 +1,236 @@
+package merger
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// Split describes how a PDF is divided into several documents. Exactly one
+// of Ranges, Every and Bookmarks must be set.
+type Split struct {
+	// Ranges become one document each, using pdfcpu's page selection
+	// syntax, e.g. "1-3" or "4,6-8".
+	Ranges []string
+	// Every splits the PDF into documents of Every pages, the last one
+	// holding the remainder.
+	Every int
+	// Bookmarks starts a new document at every top-level bookmark.
+	Bookmarks bool
+}
+
+// SplitPart is a document produced by SplitPDF.
+type SplitPart struct {
+	Path string
+	// Title is the bookmark the part was split at, if any.
+	Title string
+	// Pages lists the pages of the original PDF the part holds, e.g. "1-3".
+	Pages string
+}
+
+// Validate checks that exactly one split mode is set and that every range is
+// a valid page selection.
+func (s Split) Validate() error {
+	modes := 0
+	if len(s.Ranges) > 0 {
+		modes++
+	}
+	if s.Every != 0 {
+		modes++
+	}
+	if s.Bookmarks {
+		modes++
+	}
+	if modes != 1 {
+		return fmt.Errorf("exactly one of ranges, every and bookmarks is required")
+	}
+
+	if s.Every < 0 {
+		return fmt.Errorf("every must be positive, got %d", s.Every)
+	}
+	for _, r := range s.Ranges {
+		if _, err := api.ParsePageSelection(r); err != nil {
+			return fmt.Errorf("invalid page range %q: %w", r, err)
+		}
+	}
+	return nil
+}
+
+// SplitPDF splits the PDF at path into documents written to outDir and
+// returns them in order.
+func SplitPDF(path, outDir string, split Split) ([]SplitPart, error) {
+	if err := split.Validate(); err != nil {
+		return nil, err
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.SPLIT
+	conf.ValidationMode = model.ValidationRelaxed
+
+	ctx, err := readPart(path, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []SplitPart
+	switch {
+	case len(split.Ranges) > 0:
+		for _, r := range split.Ranges {
+			pages, err := selectPages(ctx, r)
+			if err != nil {
+				return nil, err
+			}
+			part := SplitPart{
+				Path:  partPath(outDir, path, len(parts)+1, strings.NewReplacer(" ", "", ",", "_").Replace(r)),
+				Pages: r,
+			}
+			if err := writePages(ctx, pages, part.Path); err != nil {
+				return nil, err
+			}
+			parts = append(parts, part)
+		}
+
+	case split.Every > 0:
+		for from := 1; from <= ctx.PageCount; from += split.Every {
+			thru := min(from+split.Every-1, ctx.PageCount)
+			part, err := writeSpan(ctx, path, outDir, len(parts)+1, from, thru)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, part)
+		}
+
+	default:
+		bookmarks, err := pdfcpu.Bookmarks(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bookmarks: %w", err)
+		}
+		if len(bookmarks) == 0 {
+			return nil, fmt.Errorf("%s has no bookmarks", filepath.Base(path))
+		}
+		for _, bm := range bookmarks {
+			thru := bm.PageThru
+			if thru == 0 {
+				thru = ctx.PageCount
+			}
+			part, err := writeSpan(ctx, path, outDir, len(parts)+1, bm.PageFrom, thru)
+			if err != nil {
+				return nil, err
+			}
+			part.Title = bm.Title
+			parts = append(parts, part)
+		}
+	}
+
+	slog.Info("PDF split", "input", path, "parts", len(parts))
+	return parts, nil
+}
+
+// ExtractPages writes the pages of the PDF at path matching the page
+// selection to outputFile, in document order.
+func ExtractPages(path, outputFile string, selection []string) error {
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.EXTRACTPAGES
+	conf.ValidationMode = model.ValidationRelaxed
+
+	ctx, err := readPart(path, conf)
+	if err != nil {
+		return err
+	}
+
+	pages, err := selectPages(ctx, strings.Join(selection, ","))
+	if err != nil {
+		return err
+	}
+
+	if err := writePages(ctx, pages, outputFile); err != nil {
+		return err
+	}
+
+	slog.Info("pages extracted", "input", path, "output", outputFile, "pages", len(pages))
+	return nil
+}
+
+// selectPages resolves a page selection against ctx into sorted page
+// numbers.
+func selectPages(ctx *model.Context, selection string) ([]int, error) {
+	parsed, err := api.ParsePageSelection(selection)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page selection %q: %w", selection, err)
+	}
+
+	set, err := api.PagesForPageSelection(ctx.PageCount, parsed, true, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page selection %q: %w", selection, err)
+	}
+
+	var pages []int
+	for page, selected := range set {
+		if selected {
+			pages = append(pages, page)
+		}
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("page selection %q matches no page of %d", selection, ctx.PageCount)
+	}
+
+	slices.Sort(pages)
+	return pages, nil
+}
+
+// writeSpan writes the pages from through thru of ctx to outDir as the
+// index-th part.
+func writeSpan(ctx *model.Context, path, outDir string, index, from, thru int) (SplitPart, error) {
+	pages := make([]int, 0, thru-from+1)
+	for page := from; page <= thru; page++ {
+		pages = append(pages, page)
+	}
+
+	label := spanLabel(from, thru)
+	part := SplitPart{
+		Path:  partPath(outDir, path, index, label),
+		Pages: label,
+	}
+	return part, writePages(ctx, pages, part.Path)
+}
+
+// writePages copies pages of ctx into a new PDF at outputFile.
+func writePages(ctx *model.Context, pages []int, outputFile string) error {
+	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	ctxDest, err := pdfcpu.ExtractPages(ctx, pages, false)
+	if err != nil {
+		return fmt.Errorf("failed to extract pages: %w", err)
+	}
+
+	if err := api.WriteContextFile(ctxDest, outputFile); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputFile, err)
+	}
+	return nil
+}
+
+// partPath names the index-th part of the PDF at path after its position and
+// the pages it holds, e.g. "report_2_4-6.pdf", so parts holding the same pages
+// never share a file.
+func partPath(outDir, path string, index int, label string) string {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return filepath.Join(outDir, fmt.Sprintf("%s_%d_%s.pdf", base, index, label))
+}
+
+// spanLabel formats a page span as "from-thru", or a single page number.
+func spanLabel(from, thru int) string {
+	if from == thru {
+		return strconv.Itoa(from)
+	}
+	return fmt.Sprintf("%d-%d", from, thru)
+}
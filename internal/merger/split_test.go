@@ -0,0 +1,100 @@
+package merger
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplitPDF(t *testing.T) {
+	input := writeTestPDF(t, "report.pdf", testPDF(t, 5))
+
+	tests := []struct {
+		name  string
+		split Split
+		want  [][]string
+	}{
+		{
+			name:  "ranges",
+			split: Split{Ranges: []string{"1-2", "4,5"}},
+			want:  [][]string{{"Page 1", "Page 2"}, {"Page 4", "Page 5"}},
+		},
+		{
+			name:  "duplicate ranges",
+			split: Split{Ranges: []string{"2-3", "2-3"}},
+			want:  [][]string{{"Page 2", "Page 3"}, {"Page 2", "Page 3"}},
+		},
+		{
+			name:  "every",
+			split: Split{Every: 2},
+			want:  [][]string{{"Page 1", "Page 2"}, {"Page 3", "Page 4"}, {"Page 5"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := SplitPDF(input, t.TempDir(), tt.split)
+			if err != nil {
+				t.Fatalf("SplitPDF: %v", err)
+			}
+			if len(parts) != len(tt.want) {
+				t.Fatalf("got %d parts, want %d", len(parts), len(tt.want))
+			}
+
+			var paths []string
+			for i, part := range parts {
+				if got := pageLabels(t, part.Path); !slices.Equal(got, tt.want[i]) {
+					t.Errorf("part %d holds %q, want %q", i+1, got, tt.want[i])
+				}
+				if !strings.HasPrefix(filepath.Base(part.Path), "report_") {
+					t.Errorf("part %d is named %s", i+1, part.Path)
+				}
+				if slices.Contains(paths, part.Path) {
+					t.Errorf("part %d reuses the file %s", i+1, part.Path)
+				}
+				paths = append(paths, part.Path)
+			}
+		})
+	}
+}
+
+func TestSplitValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		split   Split
+		wantErr bool
+	}{
+		{"ranges", Split{Ranges: []string{"1-3", "odd"}}, false},
+		{"every", Split{Every: 3}, false},
+		{"bookmarks", Split{Bookmarks: true}, false},
+		{"no mode", Split{}, true},
+		{"two modes", Split{Every: 2, Bookmarks: true}, true},
+		{"negative every", Split{Every: -1}, true},
+		{"invalid range", Split{Ranges: []string{"1-x"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.split.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExtractPages(t *testing.T) {
+	input := writeTestPDF(t, "report.pdf", testPDF(t, 6))
+	output := filepath.Join(t.TempDir(), "extracted.pdf")
+
+	// Pages are copied in document order, whatever the order selected.
+	if err := ExtractPages(input, output, []string{"5-6", "even", "1"}); err != nil {
+		t.Fatalf("ExtractPages: %v", err)
+	}
+	want := []string{"Page 1", "Page 2", "Page 4", "Page 5", "Page 6"}
+	if got := pageLabels(t, output); !slices.Equal(got, want) {
+		t.Errorf("extracted %q, want %q", got, want)
+	}
+
+	if err := ExtractPages(input, output, []string{"7-9"}); err == nil {
+		t.Error("selection beyond the last page succeeded")
+	}
+}
@@ -0,0 +1,174 @@
+package merger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Positions lists the anchors a watermark can be placed at: top, center and
+// bottom rows of left, center and right columns.
+var Positions = []string{"tl", "tc", "tr", "l", "c", "r", "bl", "bc", "br"}
+
+// Watermark is a text or image mark applied to the pages of a PDF. Zero
+// values fall back to the pdfcpu defaults: Helvetica gray text scaled to
+// half the page width, centered, drawn along the page diagonal.
+type Watermark struct {
+	// Text is the content of a text watermark. It may use pdfcpu's %p and
+	// %P placeholders for the page number and page count.
+	Text string
+	// Image is a PNG or JPEG used instead of Text.
+	Image []byte
+	// OnTop stamps the mark over the page content instead of behind it.
+	OnTop bool
+
+	FontName string
+	FontSize int
+	// Color is a hex color such as "#FF0000".
+	Color string
+	// Opacity ranges up to 1, fully opaque, which is also the default.
+	Opacity float64
+	// Rotation in degrees counterclockwise. Nil follows the page diagonal.
+	Rotation *float64
+	// Position is one of Positions. Defaults to "c".
+	Position string
+	// OffsetX and OffsetY move the mark away from its position, in points.
+	OffsetX float64
+	OffsetY float64
+	// Scale is the mark width relative to the page width, up to 1. When
+	// unset, an explicit FontSize is used as is.
+	Scale float64
+
+	// Pages selects the pages to mark using pdfcpu's page selection syntax,
+	// e.g. "1-3", "odd" or "!1". Empty marks every page.
+	Pages []string
+}
+
+// Validate checks that the watermark can be applied.
+func (w Watermark) Validate() error {
+	_, err := w.model()
+	return err
+}
+
+// model converts w into a pdfcpu watermark.
+func (w Watermark) model() (*model.Watermark, error) {
+	if (w.Text == "") == (len(w.Image) == 0) {
+		return nil, fmt.Errorf("watermark needs either text or an image")
+	}
+
+	for _, v := range []string{w.FontName, w.Color, w.Position} {
+		if strings.ContainsAny(v, ",:") {
+			return nil, fmt.Errorf("invalid watermark setting %q", v)
+		}
+	}
+
+	if len(w.Pages) > 0 {
+		if _, err := api.ParsePageSelection(strings.Join(w.Pages, ",")); err != nil {
+			return nil, fmt.Errorf("invalid page selection: %w", err)
+		}
+	}
+
+	desc := w.description()
+
+	var wm *model.Watermark
+	var err error
+	if w.Text != "" {
+		wm, err = api.TextWatermark(w.Text, desc, w.OnTop, false, types.POINTS)
+	} else {
+		wm, err = api.ImageWatermarkForReader(bytes.NewReader(w.Image), desc, w.OnTop, false, types.POINTS)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid watermark: %w", err)
+	}
+
+	return wm, nil
+}
+
+// description renders the settings of w as a pdfcpu watermark description.
+func (w Watermark) description() string {
+	var params []string
+	add := func(key, value string) {
+		params = append(params, key+":"+value)
+	}
+	float := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	if w.FontName != "" {
+		add("fontname", w.FontName)
+	}
+	if w.FontSize > 0 {
+		add("points", strconv.Itoa(w.FontSize))
+	}
+	if w.Color != "" {
+		add("fillcolor", w.Color)
+	}
+	if w.Opacity > 0 {
+		add("opacity", float(w.Opacity))
+	}
+	if w.Rotation != nil {
+		add("rotation", float(*w.Rotation))
+	}
+	if w.Position != "" {
+		add("position", w.Position)
+	}
+	if w.OffsetX != 0 || w.OffsetY != 0 {
+		add("offset", float(w.OffsetX)+" "+float(w.OffsetY))
+	}
+	switch {
+	case w.Scale > 0:
+		add("scalefactor", float(w.Scale)+" rel")
+	case w.FontSize > 0:
+		// Keep an explicit font size instead of scaling to the page width.
+		add("scalefactor", "1 abs")
+	}
+
+	return strings.Join(params, ", ")
+}
+
+// AddWatermarks applies every watermark, in order, to the PDF read from rs
+// and writes the result to w.
+func AddWatermarks(rs io.ReadSeeker, w io.Writer, marks []Watermark) error {
+	if len(marks) == 0 {
+		return passThrough(rs, w)
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.ADDWATERMARKS
+	conf.ValidationMode = model.ValidationRelaxed
+
+	ctx, err := readPDF(rs, conf)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	for i, mark := range marks {
+		wm, err := mark.model()
+		if err != nil {
+			return fmt.Errorf("watermark #%d: %w", i+1, err)
+		}
+
+		pages, err := api.PagesForPageSelection(ctx.PageCount, mark.Pages, true, false)
+		if err != nil {
+			return fmt.Errorf("watermark #%d: invalid page selection: %w", i+1, err)
+		}
+
+		if err := api.WatermarkContext(ctx, pages, wm); err != nil {
+			return fmt.Errorf("failed to apply watermark #%d: %w", i+1, err)
+		}
+	}
+
+	if err := api.WriteContext(ctx, w); err != nil {
+		return fmt.Errorf("failed to write watermarked PDF: %w", err)
+	}
+
+	slog.Info("watermarks applied", "count", len(marks))
+	return nil
+}
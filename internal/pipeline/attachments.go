@@ -0,0 +1,81 @@
+package pipeline
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/psilva1982/rapid_pdf/internal/converter"
+	"github.com/psilva1982/rapid_pdf/internal/merger"
+)
+
+// sourcesAttachment is the name of the attached list of sources.
+const sourcesAttachment = "sources.txt"
+
+// Attachments selects the files embedded into the merged document to record
+// its provenance.
+type Attachments struct {
+	// Sources attaches sources.txt, listing every source in merge order.
+	Sources bool
+	// Snapshots attaches the HTML of every page as rendered by Chrome.
+	// Sources that are not rendered, such as PDFs, have no snapshot.
+	Snapshots bool
+	// Files are attached as is, e.g. the request that started the job or
+	// files supplied by the caller.
+	Files []merger.Attachment
+}
+
+// Validate checks that every file can be attached.
+func (a Attachments) Validate() error {
+	for i, f := range a.Files {
+		if err := f.Validate(); err != nil {
+			return fmt.Errorf("attachment #%d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// build collects the attachments of a job that converted sources into docs,
+// in order: the list of sources, the snapshots and the files.
+func (a Attachments) build(sources []converter.Source, docs []converter.Document) []merger.Attachment {
+	var attachments []merger.Attachment
+
+	if a.Sources {
+		var b strings.Builder
+		for i, src := range sources {
+			fmt.Fprintf(&b, "%d. %s\n", i+1, sourceRef(src))
+		}
+		attachments = append(attachments, merger.Attachment{
+			Name:        sourcesAttachment,
+			Description: "Sources of the document, in merge order",
+			Data:        []byte(b.String()),
+		})
+	}
+
+	if a.Snapshots {
+		for i, doc := range docs {
+			if doc.Snapshot == nil {
+				continue
+			}
+			attachments = append(attachments, merger.Attachment{
+				Name:        fmt.Sprintf("snapshot_%03d.html", i+1),
+				Description: fmt.Sprintf("Rendered HTML of source #%d (%s)", i+1, sources[i]),
+				Data:        doc.Snapshot,
+			})
+		}
+	}
+
+	return append(attachments, a.Files...)
+}
+
+// sourceRef describes where a source came from: its URL, without any
+// password, or its name for sources that were supplied directly.
+func sourceRef(src converter.Source) string {
+	if src.URL == "" {
+		return src.String()
+	}
+	if u, err := url.Parse(src.URL); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return src.URL
+}
@@ -0,0 +1,35 @@
+package pipeline
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+
+	"github.com/psilva1982/rapid_pdf/internal/converter"
+	"github.com/psilva1982/rapid_pdf/internal/merger"
+)
+
+// defaultCoverBookmark is the bookmark title of a cover page without a title.
+const defaultCoverBookmark = "Cover"
+
+// buildCover renders the cover page and returns it as a part to be merged in
+// front of everything else, along with the documents to close.
+func buildCover(ctx context.Context, cover converter.Cover, opts Options) (merger.Part, []converter.Document, error) {
+	html, err := converter.RenderCover(cover)
+	if err != nil {
+		return merger.Part{}, nil, err
+	}
+
+	// The cover is always paginated, whatever the mode of the content.
+	render := opts.Render
+	render.SinglePage = false
+
+	title := cmp.Or(cover.Title, defaultCoverBookmark)
+	docs, err := converter.ConvertSources(ctx, []converter.Source{{HTML: html, Name: title}}, render)
+	if err != nil {
+		return merger.Part{}, docs, err
+	}
+
+	slog.Info("cover page rendered", "title", cover.Title, "custom_template", cover.Template != "")
+	return merger.Part{PDF: docs[0].PDF.Reader(), Title: title}, docs, nil
+}
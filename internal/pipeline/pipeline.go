@@ -0,0 +1,419 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/psilva1982/rapid_pdf/internal/converter"
+	"github.com/psilva1982/rapid_pdf/internal/merger"
+	"github.com/psilva1982/rapid_pdf/internal/spool"
+)
+
+// Validation modes of the final document.
+const (
+	// ValidationOff skips validation.
+	ValidationOff = "off"
+	// ValidationWarn reports problems without failing the job. It is the
+	// default.
+	ValidationWarn = "warn"
+	// ValidationFail fails the job with ErrInvalidOutput when problems are
+	// found.
+	ValidationFail = "fail"
+)
+
+// ErrInvalidOutput is returned in ValidationFail mode when the output
+// document breaks the PDF specification or, with PDFA, a PDF/A check fails.
+var ErrInvalidOutput = errors.New("output validation failed")
+
+// Options bundles the settings of every stage of a generate job.
+type Options struct {
+	// Render controls how each source is printed by Chrome.
+	Render converter.Options
+
+	// Cover, when set, is rendered as the first page of the document.
+	Cover *converter.Cover
+
+	// TOC prepends a table of contents page listing every source with its
+	// starting page.
+	TOC bool
+	// TOCTitle is the heading of the table of contents. Defaults to
+	// DefaultTOCTitle.
+	TOCTitle string
+
+	// PageLayout, when set, gives every page of the merged document the
+	// same paper size.
+	PageLayout *merger.PageLayout
+	// Duplex inserts blank pages so the cover, the table of contents, every
+	// separator and every source start on a right-hand page.
+	Duplex bool
+	// Separators, when set, inserts a blank or titled page between
+	// consecutive sources.
+	Separators *Separators
+
+	// PageNumbers stamps continuous page numbers over the merged document.
+	PageNumbers *merger.PageNumbering
+	// SkipFrontMatter leaves the cover and the table of contents
+	// unnumbered, on top of the pages skipped by PageNumbers, so numbering
+	// starts with the content.
+	SkipFrontMatter bool
+
+	// Watermarks are text or image marks applied to the merged document,
+	// in order.
+	Watermarks []merger.Watermark
+
+	// Imposition, when set, lays several pages out on each sheet or turns
+	// the document into a booklet, once page numbers and watermarks are on
+	// the pages.
+	Imposition *merger.Imposition
+
+	// Print, when set, adjusts the document for printing on paper, e.g. in
+	// grayscale and without backgrounds.
+	Print *Print
+
+	// Attachments, when set, embeds provenance files such as the list of
+	// sources into the merged document.
+	Attachments *Attachments
+
+	// Compression is one of merger.CompressionLevels. Empty disables it.
+	Compression string
+	// ImageDPI overrides the resolution images are downsampled to when
+	// compressing.
+	ImageDPI int
+
+	// Encryption, when set, protects the final document with passwords and
+	// permission flags.
+	Encryption *merger.Encryption
+
+	// PDFA makes the final document PDF/A-2b: it adds an sRGB output
+	// intent and the PDF/A identification, and checks conformance. It
+	// cannot be combined with Encryption or custom Metadata.Properties,
+	// which PDF/A requires to be mirrored in XMP.
+	PDFA bool
+
+	// Metadata is written to the document information of the final PDF.
+	Metadata merger.Metadata
+
+	// Signature, when set, digitally signs the final document with Signer.
+	Signature *merger.Signature
+	Signer    *merger.Signer
+
+	// Validation is one of ValidationOff, ValidationWarn and
+	// ValidationFail. Empty means ValidationWarn.
+	Validation string
+}
+
+// Report summarizes a finished generate job.
+type Report struct {
+	// SizeBefore is the size in bytes of the merged document before
+	// compression.
+	SizeBefore int64
+	// SizeAfter is the size in bytes of the final document.
+	SizeAfter int64
+	// ValidationErrors lists where the final document breaks the PDF
+	// specification. It is empty when validation is off.
+	ValidationErrors []string
+	// Conformance holds the outcome of every PDF/A check when PDFA is set.
+	Conformance []merger.ConformanceCheck
+}
+
+// Generate runs the full conversion pipeline shared by the API and the CLI:
+// it converts every source to PDF and merges the results into a single
+// document, with one bookmark per source, an optional cover and table of
+// contents, page numbers, watermarks, imposition, print adjustments,
+// attachments, compression, PDF/A conversion, encryption, the requested
+// document metadata and a digital signature, and finally validates the
+// result.
+// Documents are kept in memory and only spill to temporary files above
+// Render.SpillThreshold; intermediate buffers are always released. The
+// caller must close the returned buffer.
+func Generate(ctx context.Context, sources []converter.Source, opts Options) (*spool.Buffer, Report, error) {
+	var report Report
+
+	// Print settings apply to every page Chrome renders, including the
+	// cover, the table of contents and the separators
+	var printing Print
+	if opts.Print != nil {
+		printing = opts.Print.resolve()
+		opts.Render.OmitBackground = printing.OmitBackground
+		opts.Render.HideLinks = printing.StripLinks
+	}
+
+	// 1. Convert all sources to individual PDFs, keeping the rendered HTML
+	// of each page when it is to be attached
+	render := opts.Render
+	render.Snapshot = opts.Attachments != nil && opts.Attachments.Snapshots
+	docs, err := converter.ConvertSources(ctx, sources, render)
+	// Ensure intermediate buffers, including partial results, are released
+	defer converter.CloseAll(docs)
+	if err != nil {
+		return nil, report, fmt.Errorf("conversion failed: %w", err)
+	}
+
+	// 2. Merge PDFs, behind the table of contents, into a single document
+	parts := make([]merger.Part, len(docs))
+	for i, doc := range docs {
+		parts[i] = merger.Part{
+			PDF:   doc.PDF.Reader(),
+			Title: sources[i].Label,
+			Name:  sources[i].String(),
+			URL:   sources[i].URL,
+		}
+	}
+
+	if s := opts.Separators; s != nil {
+		separatorDocs, err := s.attach(ctx, parts, opts)
+		defer converter.CloseAll(separatorDocs)
+		if err != nil {
+			return nil, report, fmt.Errorf("separator pages failed: %w", err)
+		}
+	}
+
+	// The cover and the table of contents make up the front matter, which
+	// comes before the content in that order. With duplex padding, the
+	// blank pages behind them belong to the front matter too.
+	mergeOpts := opts.mergeOptions()
+	var frontParts []merger.Part
+	frontMatter := 0
+	if opts.Cover != nil {
+		cover, coverDocs, err := buildCover(ctx, *opts.Cover, opts)
+		defer converter.CloseAll(coverDocs)
+		if err != nil {
+			return nil, report, fmt.Errorf("cover page failed: %w", err)
+		}
+		if frontMatter, err = merger.PageCount(cover.PDF); err != nil {
+			return nil, report, fmt.Errorf("cover page failed: %w", err)
+		}
+		frontMatter += mergeOpts.Padding(frontMatter)
+		frontParts = append(frontParts, cover)
+	}
+
+	if opts.TOC {
+		toc, tocDocs, err := buildTOC(ctx, parts, frontMatter, opts)
+		defer converter.CloseAll(tocDocs)
+		if err != nil {
+			return nil, report, fmt.Errorf("table of contents failed: %w", err)
+		}
+		tocPages, err := merger.PageCount(toc.PDF)
+		if err != nil {
+			return nil, report, fmt.Errorf("table of contents failed: %w", err)
+		}
+		frontMatter += tocPages
+		frontMatter += mergeOpts.Padding(frontMatter)
+		frontParts = append(frontParts, toc)
+	}
+	parts = append(frontParts, parts...)
+
+	out := spool.New(render.SpillThreshold)
+	if err := merger.MergePDFs(parts, mergeOpts, out); err != nil {
+		out.Close()
+		return nil, report, fmt.Errorf("merge failed: %w", err)
+	}
+
+	// 3. Post-process the merged document. Each stage that rewrites it reads
+	// the current buffer and writes a fresh one.
+	stages := pdfStages{threshold: render.SpillThreshold, out: out}
+	defer stages.close()
+
+	if numbering := opts.pageNumbering(frontMatter); numbering != nil {
+		err := stages.run("page numbering", func(rs io.ReadSeeker, w io.Writer) error {
+			return merger.AddPageNumbers(rs, w, *numbering)
+		})
+		if err != nil {
+			return nil, report, err
+		}
+	}
+
+	if len(opts.Watermarks) > 0 {
+		err := stages.run("watermark", func(rs io.ReadSeeker, w io.Writer) error {
+			return merger.AddWatermarks(rs, w, opts.Watermarks)
+		})
+		if err != nil {
+			return nil, report, err
+		}
+	}
+
+	if imp := opts.Imposition; imp != nil {
+		err := stages.run("imposition", func(rs io.ReadSeeker, w io.Writer) error {
+			return merger.Impose(rs, w, *imp)
+		})
+		if err != nil {
+			return nil, report, err
+		}
+	}
+
+	if printOpts := printing.options(); !printOpts.IsZero() {
+		err := stages.run("print preparation", func(rs io.ReadSeeker, w io.Writer) error {
+			return merger.PreparePrint(rs, w, printOpts)
+		})
+		if err != nil {
+			return nil, report, err
+		}
+	}
+
+	if a := opts.Attachments; a != nil {
+		if attachments := a.build(sources, docs); len(attachments) > 0 {
+			err := stages.run("attachments", func(rs io.ReadSeeker, w io.Writer) error {
+				return merger.AddAttachments(rs, w, attachments)
+			})
+			if err != nil {
+				return nil, report, err
+			}
+		}
+	}
+
+	report.SizeBefore = stages.out.Size()
+	if opts.Compression != "" && opts.Compression != merger.CompressionNone {
+		err := stages.run("compression", func(rs io.ReadSeeker, w io.Writer) error {
+			return merger.Compress(rs, w, opts.Compression, opts.ImageDPI)
+		})
+		if err != nil {
+			return nil, report, err
+		}
+	}
+
+	if opts.PDFA {
+		if opts.Encryption != nil {
+			return nil, report, fmt.Errorf("PDF/A conversion failed: PDF/A documents cannot be encrypted")
+		}
+		if len(opts.Metadata.Properties) > 0 {
+			return nil, report, fmt.Errorf("PDF/A conversion failed: custom properties have no XMP counterpart")
+		}
+		err := stages.run("PDF/A conversion", merger.ConvertPDFA)
+		if err != nil {
+			return nil, report, err
+		}
+	}
+
+	var password string
+	if enc := opts.Encryption; enc != nil {
+		err := stages.run("encryption", func(rs io.ReadSeeker, w io.Writer) error {
+			return merger.Encrypt(rs, w, *enc)
+		})
+		if err != nil {
+			return nil, report, err
+		}
+		password = enc.UserPassword
+	}
+
+	// 4. Document information goes last, as rewriting the file resets it
+	meta := opts.Metadata
+	meta.PDFA = opts.PDFA
+	if !meta.IsZero() {
+		if err := merger.SetMetadata(stages.out, meta, password); err != nil {
+			return nil, report, fmt.Errorf("metadata failed: %w", err)
+		}
+	}
+
+	// 5. The signature covers every byte written so far, so it comes last
+	if sig := opts.Signature; sig != nil {
+		if opts.Signer == nil {
+			return nil, report, fmt.Errorf("signing failed: no signing certificate configured")
+		}
+		if err := merger.Sign(ctx, stages.out, opts.Signer, *sig); err != nil {
+			return nil, report, fmt.Errorf("signing failed: %w", err)
+		}
+	}
+
+	report.SizeAfter = stages.out.Size()
+
+	// 6. Check the final document
+	if err := Validate(stages.out, opts.Validation, password, opts.PDFA, &report); err != nil {
+		return nil, report, err
+	}
+
+	return stages.release(), report, nil
+}
+
+// Validate checks the document read from rs according to mode, one of
+// ValidationOff, ValidationWarn and ValidationFail, and records the problems
+// found in report. pdfa adds the PDF/A checks and password opens encrypted
+// documents. Every operation that writes a PDF checks it here before saving,
+// and in ValidationFail mode problems are returned as ErrInvalidOutput.
+func Validate(rs io.ReadSeeker, mode, password string, pdfa bool, report *Report) error {
+	if mode == ValidationOff {
+		return nil
+	}
+
+	problems := merger.ValidatePDF(rs, password)
+	report.ValidationErrors = problems
+
+	if pdfa {
+		checks, err := merger.CheckPDFA(rs, problems)
+		if err != nil {
+			return fmt.Errorf("PDF/A check failed: %w", err)
+		}
+		report.Conformance = checks
+		for _, c := range checks {
+			// Syntax problems are already part of the validation errors.
+			if !c.Passed && c.Name != merger.CheckSyntax {
+				problems = append(problems, fmt.Sprintf("PDF/A %s: %s", c.Name, c.Detail))
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	slog.Warn("output validation found problems", "problems", problems)
+	if mode == ValidationFail {
+		return fmt.Errorf("%w: %s", ErrInvalidOutput, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// pdfStages threads the document through the post-processing stages.
+type pdfStages struct {
+	threshold int64
+	out       *spool.Buffer
+}
+
+// run applies a rewriting stage to the current document, replacing it with
+// the result. name describes the stage in errors.
+func (s *pdfStages) run(name string, stage func(rs io.ReadSeeker, w io.Writer) error) error {
+	next := spool.New(s.threshold)
+	if err := stage(s.out.Reader(), next); err != nil {
+		next.Close()
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	s.out.Close()
+	s.out = next
+	return nil
+}
+
+// release hands the current document over to the caller.
+func (s *pdfStages) release() *spool.Buffer {
+	out := s.out
+	s.out = nil
+	return out
+}
+
+// close releases the current document unless it was handed over.
+func (s *pdfStages) close() {
+	if s.out != nil {
+		s.out.Close()
+	}
+}
+
+// mergeOptions returns the settings of the merge stage.
+func (o Options) mergeOptions() merger.MergeOptions {
+	return merger.MergeOptions{Layout: o.PageLayout, Duplex: o.Duplex}
+}
+
+// pageNumbering returns the page numbering of a document starting with
+// frontMatter cover and table of contents pages, or nil when numbering is
+// disabled.
+func (o Options) pageNumbering(frontMatter int) *merger.PageNumbering {
+	if o.PageNumbers == nil {
+		return nil
+	}
+
+	numbering := *o.PageNumbers
+	if o.SkipFrontMatter {
+		numbering.Skip += frontMatter
+	}
+	return &numbering
+}
@@ -0,0 +1,60 @@
+package pipeline
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/psilva1982/rapid_pdf/internal/merger"
+)
+
+// Print presets bundling the settings of Print.
+const (
+	// PrintPresetTonerSaver omits backgrounds, prints in grayscale and
+	// strips links.
+	PrintPresetTonerSaver = "toner_saver"
+	// PrintPresetGrayscale prints in grayscale only.
+	PrintPresetGrayscale = "grayscale"
+)
+
+// PrintPresets lists the accepted print presets.
+var PrintPresets = []string{PrintPresetTonerSaver, PrintPresetGrayscale}
+
+// Print adjusts every source of a batch, along with the cover, table of
+// contents and separators, for printing on paper.
+type Print struct {
+	// Preset is one of PrintPresets. It turns its settings on in addition
+	// to the ones below.
+	Preset string
+	// OmitBackground prints rendered pages without background colors and
+	// images. PDFs are copied unchanged and keep theirs.
+	OmitBackground bool
+	// Grayscale renders every page in shades of gray.
+	Grayscale bool
+	// StripLinks prints links like plain text and removes them from the
+	// document.
+	StripLinks bool
+}
+
+// Validate checks that the preset exists.
+func (p Print) Validate() error {
+	if p.Preset != "" && !slices.Contains(PrintPresets, p.Preset) {
+		return fmt.Errorf("unknown preset %q", p.Preset)
+	}
+	return nil
+}
+
+// resolve returns p with the settings of its preset turned on.
+func (p Print) resolve() Print {
+	switch p.Preset {
+	case PrintPresetTonerSaver:
+		p.OmitBackground, p.Grayscale, p.StripLinks = true, true, true
+	case PrintPresetGrayscale:
+		p.Grayscale = true
+	}
+	return p
+}
+
+// options returns the post-processing settings of the merged document.
+func (p Print) options() merger.PrintOptions {
+	return merger.PrintOptions{Grayscale: p.Grayscale, StripLinks: p.StripLinks}
+}
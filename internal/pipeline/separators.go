@@ -0,0 +1,73 @@
+package pipeline
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/psilva1982/rapid_pdf/internal/converter"
+	"github.com/psilva1982/rapid_pdf/internal/merger"
+)
+
+// Separators selects the pages inserted between consecutive sources.
+type Separators struct {
+	// Titled renders a page announcing the title of the next source instead
+	// of a blank page.
+	Titled bool
+	// Template is a custom HTML template for titled separators, receiving
+	// {{.Title}}, {{.Number}} and {{.Total}}. It implies Titled.
+	Template string
+}
+
+// Validate checks that the custom template parses.
+func (s Separators) Validate() error {
+	return converter.Separator{Template: s.Template}.Validate()
+}
+
+// attach sets the separator of every part but the first, rendering titled
+// pages when asked for, and returns the documents to close.
+func (s Separators) attach(ctx context.Context, parts []merger.Part, opts Options) ([]converter.Document, error) {
+	if len(parts) < 2 {
+		return nil, nil
+	}
+
+	if !s.Titled && s.Template == "" {
+		for i := range parts[1:] {
+			parts[i+1].Separator = &merger.Separator{}
+		}
+		return nil, nil
+	}
+
+	sections, err := merger.Sections(parts, merger.MergeOptions{}, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]converter.Source, 0, len(parts)-1)
+	for i, section := range sections[1:] {
+		html, err := converter.RenderSeparator(converter.Separator{
+			Title:    section.Title,
+			Number:   i + 2,
+			Total:    len(parts),
+			Template: s.Template,
+		})
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, converter.Source{HTML: html, Name: section.Title})
+	}
+
+	// Separators are always paginated, whatever the mode of the content.
+	render := opts.Render
+	render.SinglePage = false
+
+	docs, err := converter.ConvertSources(ctx, sources, render)
+	if err != nil {
+		return docs, err
+	}
+	for i, doc := range docs {
+		parts[i+1].Separator = &merger.Separator{PDF: doc.PDF.Reader()}
+	}
+
+	slog.Info("separator pages rendered", "count", len(docs), "custom_template", s.Template != "")
+	return docs, nil
+}
@@ -0,0 +1,87 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/psilva1982/rapid_pdf/internal/converter"
+	"github.com/psilva1982/rapid_pdf/internal/merger"
+)
+
+// DefaultTOCTitle is the heading of the table of contents page.
+const DefaultTOCTitle = "Table of Contents"
+
+// maxTOCRenders bounds how often the table of contents is re-rendered while
+// its own page count settles.
+const maxTOCRenders = 3
+
+// buildTOC renders the table of contents for parts and returns it as a part
+// to be merged in front of them, after frontMatter pages such as a cover,
+// along with the documents to close. Page
+// numbers account for the TOC's own pages, so it is re-rendered until the
+// page count it assumed matches the one it printed to. With page numbering
+// enabled, entries show the stamped numbers instead of physical pages.
+func buildTOC(ctx context.Context, parts []merger.Part, frontMatter int, opts Options) (merger.Part, []converter.Document, error) {
+	title := opts.TOCTitle
+	if title == "" {
+		title = DefaultTOCTitle
+	}
+
+	// The TOC is always paginated, whatever the mode of the content.
+	render := opts.Render
+	render.SinglePage = false
+
+	mergeOpts := opts.mergeOptions()
+	tocPages := 1
+	for range maxTOCRenders {
+		front := frontMatter + tocPages
+		front += mergeOpts.Padding(front)
+
+		sections, err := merger.Sections(parts, mergeOpts, front+1)
+		if err != nil {
+			return merger.Part{}, nil, err
+		}
+
+		numbering := opts.pageNumbering(front)
+		entries := make([]converter.TOCEntry, len(sections))
+		for i, s := range sections {
+			page := s.PageFrom
+			if numbering != nil {
+				if n, ok := numbering.Number(s.PageFrom); ok {
+					page = n
+				}
+			}
+			entries[i] = converter.TOCEntry{
+				Title: s.Title,
+				Page:  page,
+				Link:  merger.PageLinkURL(s.PageFrom),
+			}
+		}
+
+		html, err := converter.RenderTOC(title, entries)
+		if err != nil {
+			return merger.Part{}, nil, err
+		}
+
+		docs, err := converter.ConvertSources(ctx, []converter.Source{{HTML: html, Name: title}}, render)
+		if err != nil {
+			return merger.Part{}, docs, err
+		}
+
+		pages, err := merger.PageCount(docs[0].PDF.Reader())
+		if err != nil {
+			return merger.Part{}, docs, err
+		}
+
+		if pages == tocPages {
+			slog.Info("table of contents rendered", "entries", len(entries), "pages", pages)
+			return merger.Part{PDF: docs[0].PDF.Reader(), Title: title}, docs, nil
+		}
+
+		converter.CloseAll(docs)
+		tocPages = pages
+	}
+
+	return merger.Part{}, nil, fmt.Errorf("page count did not settle after %d renders", maxTOCRenders)
+}
@@ -0,0 +1,170 @@
+// Package spool provides a file-like buffer that keeps small documents in
+// memory and spills large ones to a temporary file, so small jobs never
+// touch the disk.
+package spool
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// DefaultThreshold is the size above which a Buffer spills to disk when no
+// threshold is given.
+const DefaultThreshold = 32 << 20
+
+// Buffer is an in-memory io.ReadWriteSeeker that moves its content to a
+// temporary file once it grows beyond its threshold. It must be closed to
+// remove that file. A Buffer is not safe for concurrent use.
+type Buffer struct {
+	threshold int64
+	mem       []byte
+	file      *os.File
+	size      int64
+	offset    int64
+}
+
+// New returns an empty Buffer that spills to disk above threshold bytes. A
+// threshold of zero or less uses DefaultThreshold.
+func New(threshold int64) *Buffer {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	return &Buffer{threshold: threshold}
+}
+
+// Size returns the length of the content in bytes.
+func (b *Buffer) Size() int64 {
+	return b.size
+}
+
+// Spilled reports whether the content has moved to a temporary file.
+func (b *Buffer) Spilled() bool {
+	return b.file != nil
+}
+
+// Reader returns a reader over the whole content, independent of the
+// offset of b. It is only valid until the next write.
+func (b *Buffer) Reader() *io.SectionReader {
+	return io.NewSectionReader(b, 0, b.size)
+}
+
+// Read implements io.Reader.
+func (b *Buffer) Read(p []byte) (int, error) {
+	n, err := b.ReadAt(p, b.offset)
+	b.offset += int64(n)
+	return n, err
+}
+
+// ReadAt implements io.ReaderAt.
+func (b *Buffer) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("spool: negative offset")
+	}
+	if off >= b.size {
+		return 0, io.EOF
+	}
+	if b.file != nil {
+		n, err := b.file.ReadAt(p[:min(int64(len(p)), b.size-off)], off)
+		if err == nil && n < len(p) {
+			err = io.EOF
+		}
+		return n, err
+	}
+
+	n := copy(p, b.mem[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Write implements io.Writer.
+func (b *Buffer) Write(p []byte) (int, error) {
+	n, err := b.WriteAt(p, b.offset)
+	b.offset += int64(n)
+	return n, err
+}
+
+// WriteAt implements io.WriterAt, spilling to disk when the write takes the
+// content beyond the threshold.
+func (b *Buffer) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("spool: negative offset")
+	}
+
+	end := off + int64(len(p))
+	if b.file == nil && end > b.threshold {
+		if err := b.spill(); err != nil {
+			return 0, err
+		}
+	}
+
+	if b.file != nil {
+		n, err := b.file.WriteAt(p, off)
+		b.size = max(b.size, off+int64(n))
+		return n, err
+	}
+
+	if end > int64(len(b.mem)) {
+		b.mem = append(b.mem, make([]byte, end-int64(len(b.mem)))...)
+	}
+	copy(b.mem[off:], p)
+	b.size = max(b.size, end)
+	return len(p), nil
+}
+
+// Seek implements io.Seeker.
+func (b *Buffer) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += b.offset
+	case io.SeekEnd:
+		offset += b.size
+	default:
+		return 0, errors.New("spool: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("spool: negative position")
+	}
+	b.offset = offset
+	return offset, nil
+}
+
+// Close releases the content, removing the temporary file if there is one.
+func (b *Buffer) Close() error {
+	b.mem = nil
+	b.size, b.offset = 0, 0
+	if b.file == nil {
+		return nil
+	}
+
+	f := b.file
+	b.file = nil
+	err := f.Close()
+	if rmErr := os.Remove(f.Name()); rmErr != nil && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// spill moves the content to a temporary file.
+func (b *Buffer) spill() error {
+	f, err := os.CreateTemp("", "rapid_pdf_spool_*.pdf")
+	if err != nil {
+		return fmt.Errorf("failed to create spool file: %w", err)
+	}
+	if _, err := f.Write(b.mem[:b.size]); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return fmt.Errorf("failed to write spool file: %w", err)
+	}
+
+	slog.Info("buffer spilled to disk", "path", f.Name(), "threshold_bytes", b.threshold)
+	b.file = f
+	b.mem = nil
+	return nil
+}
@@ -0,0 +1,158 @@
+package spool
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+)
+
+// content reads the whole content of b through Reader.
+func content(t *testing.T, b *Buffer) []byte {
+	t.Helper()
+	data, err := io.ReadAll(b.Reader())
+	if err != nil {
+		t.Fatalf("failed to read buffer: %v", err)
+	}
+	return data
+}
+
+func TestBufferInMemory(t *testing.T) {
+	b := New(16)
+	defer b.Close()
+
+	if _, err := b.Write([]byte("hello ")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Write([]byte("world")); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.Spilled() {
+		t.Error("buffer below the threshold spilled to disk")
+	}
+	if got := content(t, b); string(got) != "hello world" {
+		t.Errorf("content = %q, want %q", got, "hello world")
+	}
+	if b.Size() != 11 {
+		t.Errorf("Size() = %d, want 11", b.Size())
+	}
+}
+
+func TestBufferSpill(t *testing.T) {
+	b := New(16)
+	defer b.Close()
+
+	first := bytes.Repeat([]byte("a"), 10)
+	second := bytes.Repeat([]byte("b"), 10)
+	if _, err := b.Write(first); err != nil {
+		t.Fatal(err)
+	}
+	if b.Spilled() {
+		t.Fatal("buffer spilled before reaching the threshold")
+	}
+	if _, err := b.Write(second); err != nil {
+		t.Fatal(err)
+	}
+	if !b.Spilled() {
+		t.Fatal("buffer did not spill beyond the threshold")
+	}
+
+	want := append(append([]byte{}, first...), second...)
+	if got := content(t, b); !bytes.Equal(got, want) {
+		t.Errorf("content = %q, want %q", got, want)
+	}
+
+	// Reading through the offset continues where the writes left off.
+	if _, err := b.Seek(5, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	rest, err := io.ReadAll(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(rest, want[5:]) {
+		t.Errorf("read from offset 5 = %q, want %q", rest, want[5:])
+	}
+}
+
+func TestBufferWriteAtAcrossThreshold(t *testing.T) {
+	b := New(8)
+	defer b.Close()
+
+	if _, err := b.Write([]byte("0123")); err != nil {
+		t.Fatal(err)
+	}
+	// Writing past the end crosses the threshold and leaves a zero gap.
+	if _, err := b.WriteAt([]byte("XY"), 10); err != nil {
+		t.Fatal(err)
+	}
+	if !b.Spilled() {
+		t.Fatal("write beyond the threshold did not spill")
+	}
+	// Overwrite the middle of the spilled content.
+	if _, err := b.WriteAt([]byte("ab"), 2); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []byte("01ab\x00\x00\x00\x00\x00\x00XY")
+	if got := content(t, b); !bytes.Equal(got, want) {
+		t.Errorf("content = %q, want %q", got, want)
+	}
+	if b.Size() != int64(len(want)) {
+		t.Errorf("Size() = %d, want %d", b.Size(), len(want))
+	}
+
+	p := make([]byte, 4)
+	n, err := b.ReadAt(p, 10)
+	if n != 2 || !errors.Is(err, io.EOF) || string(p[:n]) != "XY" {
+		t.Errorf("ReadAt at the end = %d, %v, %q; want 2, EOF, \"XY\"", n, err, p[:n])
+	}
+}
+
+func TestBufferSeek(t *testing.T) {
+	b := New(0)
+	defer b.Close()
+
+	if _, err := b.Write([]byte("abcdef")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		offset int64
+		whence int
+		want   int64
+	}{
+		{2, io.SeekStart, 2},
+		{1, io.SeekCurrent, 3},
+		{-1, io.SeekEnd, 5},
+	}
+	for _, tt := range tests {
+		got, err := b.Seek(tt.offset, tt.whence)
+		if err != nil || got != tt.want {
+			t.Errorf("Seek(%d, %d) = %d, %v; want %d", tt.offset, tt.whence, got, err, tt.want)
+		}
+	}
+	if _, err := b.Seek(-10, io.SeekEnd); err == nil {
+		t.Error("Seek before the start succeeded")
+	}
+}
+
+func TestBufferCloseRemovesFile(t *testing.T) {
+	b := New(4)
+	if _, err := b.Write([]byte("spilled")); err != nil {
+		t.Fatal(err)
+	}
+	name := b.file.Name()
+
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := os.Stat(name); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("spool file %s still exists after Close: %v", name, err)
+	}
+	if b.Size() != 0 || b.Spilled() {
+		t.Error("closed buffer still holds content")
+	}
+}
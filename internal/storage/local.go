@@ -2,10 +2,14 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -26,14 +30,23 @@ func NewLocalStorage(basePath string) (*LocalStorage, error) {
 	return &LocalStorage{basePath: basePath}, nil
 }
 
-// Save writes the PDF data to a file in the local media directory.
-// It generates a unique filename using UUID + timestamp and returns
-// a relative URL path like "/media/<filename>.pdf".
-func (ls *LocalStorage) Save(_ context.Context, _ string, data []byte) (string, error) {
+// SaveStream copies body to a file in the local media directory. It
+// generates a unique filename using UUID + timestamp and returns a relative
+// URL path like "/media/<filename>.pdf".
+func (ls *LocalStorage) SaveStream(_ context.Context, _ string, body io.ReadSeeker) (string, error) {
 	filename := generateFilename()
 	filePath := filepath.Join(ls.basePath, filename)
 
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
+	f, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to write file to %s: %w", filePath, err)
+	}
+	size, err := io.Copy(f, body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(filePath)
 		return "", fmt.Errorf("failed to write file to %s: %w", filePath, err)
 	}
 
@@ -42,12 +55,39 @@ func (ls *LocalStorage) Save(_ context.Context, _ string, data []byte) (string,
 	slog.Info("file saved locally",
 		"path", filePath,
 		"url", fileURL,
-		"size_bytes", len(data),
+		"size_bytes", size,
 	)
 
 	return fileURL, nil
 }
 
+// Load opens a file of the local media directory. ref is either the
+// "/media/<filename>" URL returned by SaveStream, optionally with a
+// scheme and host, or the bare filename. Anything outside of the media
+// directory is rejected.
+func (ls *LocalStorage) Load(_ context.Context, ref string) (io.ReadCloser, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file reference %q: %w", ref, err)
+	}
+
+	filename := strings.TrimPrefix(u.Path, "/media/")
+	if filename == "" || filename == "." || filename == ".." || strings.ContainsAny(filename, `/\`) {
+		return nil, fmt.Errorf("invalid file reference %q", ref)
+	}
+
+	filePath := filepath.Join(ls.basePath, filename)
+	f, err := os.Open(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", ref, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+
+	return f, nil
+}
+
 // generateFilename creates a unique filename with timestamp and UUID.
 func generateFilename() string {
 	ts := time.Now().Format("20060102_150405")
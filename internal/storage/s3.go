@@ -1,16 +1,19 @@
 package storage
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/google/uuid"
 	"github.com/psilva1982/rapid_pdf/internal/config"
 )
@@ -47,33 +50,69 @@ func NewS3Storage(cfg *config.Config) (*S3Storage, error) {
 	}, nil
 }
 
-// Save uploads the PDF data to S3 and returns the public URL of the object.
-func (ss *S3Storage) Save(ctx context.Context, _ string, data []byte) (string, error) {
+// SaveStream uploads the content of body to S3 and returns the public URL of
+// the object.
+func (ss *S3Storage) SaveStream(ctx context.Context, _ string, body io.ReadSeeker) (string, error) {
 	key := generateS3Key()
 
+	size, err := body.Seek(0, io.SeekEnd)
+	if err != nil {
+		return "", fmt.Errorf("failed to size upload: %w", err)
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to size upload: %w", err)
+	}
+
 	input := &s3.PutObjectInput{
-		Bucket:      aws.String(ss.bucket),
-		Key:         aws.String(key),
-		Body:        bytes.NewReader(data),
-		ContentType: aws.String("application/pdf"),
+		Bucket:        aws.String(ss.bucket),
+		Key:           aws.String(key),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+		ContentType:   aws.String("application/pdf"),
 	}
 
 	if _, err := ss.client.PutObject(ctx, input); err != nil {
 		return "", fmt.Errorf("failed to upload to S3: %w", err)
 	}
 
-	fileURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", ss.bucket, ss.region, key)
+	fileURL := ss.objectURL(key)
 
 	slog.Info("file uploaded to S3",
 		"bucket", ss.bucket,
 		"key", key,
 		"url", fileURL,
-		"size_bytes", len(data),
+		"size_bytes", size,
 	)
 
 	return fileURL, nil
 }
 
+// Load opens an object of the bucket for download. ref is either the URL
+// returned by SaveStream, or the object key.
+func (ss *S3Storage) Load(ctx context.Context, ref string) (io.ReadCloser, error) {
+	key := strings.TrimPrefix(ref, ss.objectURL(""))
+
+	out, err := ss.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(ss.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var noSuchKey *s3types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, fmt.Errorf("%s: %w", ref, ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to download from S3: %w", err)
+	}
+
+	slog.Info("downloading file from S3", "bucket", ss.bucket, "key", key, "size_bytes", aws.ToInt64(out.ContentLength))
+	return out.Body, nil
+}
+
+// objectURL returns the public URL of the object stored under key.
+func (ss *S3Storage) objectURL(key string) string {
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", ss.bucket, ss.region, key)
+}
+
 // generateS3Key creates a unique S3 object key with date prefix for organization.
 func generateS3Key() string {
 	now := time.Now()
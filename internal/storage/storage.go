@@ -2,6 +2,8 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log/slog"
 
 	"github.com/psilva1982/rapid_pdf/internal/config"
@@ -11,11 +13,20 @@ import (
 // Implementations handle where the file is stored (local disk, S3, etc.)
 // and return a URL that can be used to retrieve the file.
 type Storage interface {
-	// Save persists the given data under the specified filename and returns
-	// a URL where the file can be accessed.
-	Save(ctx context.Context, filename string, data []byte) (fileURL string, err error)
+	// SaveStream persists the content of body under the specified filename
+	// and returns a URL where the file can be accessed. The content is
+	// streamed, so large files are never held in memory.
+	SaveStream(ctx context.Context, filename string, body io.ReadSeeker) (fileURL string, err error)
+
+	// Load opens a previously saved file for reading, given either the URL
+	// returned by SaveStream, or its storage key. The caller closes
+	// the returned reader. It returns ErrNotFound when there is no such file.
+	Load(ctx context.Context, ref string) (io.ReadCloser, error)
 }
 
+// ErrNotFound is returned by Load when the requested file does not exist.
+var ErrNotFound = errors.New("file not found in storage")
+
 // New creates the appropriate Storage implementation based on configuration.
 // If AWS S3 credentials are present in the config, it returns an S3Storage;
 // otherwise, it returns a LocalStorage that saves files to ./media.
@@ -1,11 +1,18 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,7 +21,10 @@ import (
 	"github.com/psilva1982/rapid_pdf/internal/api"
 	"github.com/psilva1982/rapid_pdf/internal/config"
 	"github.com/psilva1982/rapid_pdf/internal/converter"
+	"github.com/psilva1982/rapid_pdf/internal/markdown"
 	"github.com/psilva1982/rapid_pdf/internal/merger"
+	"github.com/psilva1982/rapid_pdf/internal/pipeline"
+	"github.com/psilva1982/rapid_pdf/internal/spool"
 	"github.com/psilva1982/rapid_pdf/internal/storage"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -38,8 +48,9 @@ const defaultOutputFile = "output.pdf"
 // @BasePath  /
 
 func main() {
-	// Set up structured logging.
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+	// Set up structured logging on stderr, keeping stdout for output such as
+	// the inspect report.
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
 	}))
 	slog.SetDefault(logger)
@@ -55,9 +66,20 @@ func main() {
 
 	// Check if running in CLI mode (arguments provided) or Server mode (no arguments)
 	args := os.Args[1:]
-	if len(args) == 0 {
+	switch {
+	case len(args) == 0:
 		runServer(cfg)
-	} else {
+	case args[0] == "split":
+		runSplit(args[1:])
+	case args[0] == "extract":
+		runExtract(args[1:])
+	case args[0] == "fill":
+		runFill(args[1:])
+	case args[0] == "impose":
+		runImpose(args[1:])
+	case args[0] == "inspect":
+		runInspect(args[1:])
+	default:
 		runCLI(cfg, args)
 	}
 }
@@ -72,8 +94,15 @@ func runServer(cfg *config.Config) {
 		os.Exit(1)
 	}
 
-	// Initialize API handler with configuration and storage.
-	handler := api.NewHandler(cfg, store)
+	// Load the signing certificate, when configured.
+	signer, err := loadSigner(cfg)
+	if err != nil {
+		slog.Error("failed to load signing certificate", "error", err)
+		os.Exit(1)
+	}
+
+	// Initialize API handler with configuration, storage and signer.
+	handler := api.NewHandler(cfg, store, signer)
 
 	r := gin.Default()
 
@@ -83,6 +112,13 @@ func runServer(cfg *config.Config) {
 	}
 
 	r.POST("/generate", handler.GeneratePDF)
+	r.POST("/generate/upload", handler.GenerateFromUpload)
+	r.POST("/split", handler.SplitPDF)
+	r.POST("/extract", handler.ExtractPages)
+	r.POST("/form/fields", handler.ListFormFields)
+	r.POST("/form/fill", handler.FillForm)
+	r.POST("/impose", handler.ImposePDF)
+	r.POST("/inspect", handler.InspectPDF)
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	if err := r.Run(":" + cfg.Port); err != nil {
@@ -91,7 +127,147 @@ func runServer(cfg *config.Config) {
 	}
 }
 
-func runCLI(cfg *config.Config, urls []string) {
+func runCLI(cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("rapid_pdf", flag.ExitOnError)
+	theme := fs.String("theme", markdown.DefaultTheme,
+		"built-in theme for Markdown sources ("+strings.Join(markdown.Themes(), ", ")+")")
+	stylesheet := fs.String("stylesheet", "", "path to a custom CSS file applied to Markdown sources")
+	singlePage := fs.Bool("single-page", false, "print each source on one continuous page instead of A4 pages")
+	locale := fs.String("locale", "", "browser locale for dates, numbers and currency (e.g. pt-BR)")
+	timezoneID := fs.String("timezone", "", "browser timezone (e.g. America/Sao_Paulo)")
+	acceptLanguage := fs.String("accept-language", "", "Accept-Language header (defaults to -locale)")
+	geolocation := fs.String("geolocation", "", "emulated position as lat,lon[,accuracy]")
+	cover := fs.Bool("cover", false, "prepend a cover page (implied by the -cover-* flags)")
+	coverTitle := fs.String("cover-title", "", "cover page title")
+	coverSubtitle := fs.String("cover-subtitle", "", "cover page subtitle")
+	coverAuthor := fs.String("cover-author", "", "author printed on the cover page")
+	coverDate := fs.String("cover-date", "", "date printed on the cover page (default today)")
+	coverLogo := fs.String("cover-logo", "", "path to a PNG, JPEG, GIF, WebP or SVG logo for the cover page")
+	coverTemplate := fs.String("cover-template", "", "path to a custom HTML template for the cover page")
+	toc := fs.Bool("toc", false, "prepend a table of contents listing every source")
+	tocTitle := fs.String("toc-title", pipeline.DefaultTOCTitle, "heading of the table of contents")
+	paperSize := fs.String("paper-size", "", "scale every page to this paper size, e.g. A4 or Letter (implied by the other layout flags)")
+	landscape := fs.Bool("landscape", false, "turn the -paper-size paper sideways")
+	pageScale := fs.String("page-scale", "", "how pages are scaled to the paper ("+strings.Join(merger.ScaleModes, ", ")+") (default fit)")
+	autoRotate := fs.Bool("auto-rotate", false, "rotate pages whose orientation differs from the paper")
+	pageMargin := fs.Float64("page-margin", 0, "blank border on every edge of the paper in points")
+	duplex := fs.Bool("duplex", false, "insert blank pages so every source starts on a right-hand page")
+	separators := fs.Bool("separators", false, "insert a blank page between sources (implied by the -separator-* flags)")
+	separatorTitles := fs.Bool("separator-titles", false, "announce the title of the next source on separator pages")
+	separatorTemplate := fs.String("separator-template", "", "path to a custom HTML template for titled separator pages")
+	pageNumbers := fs.Bool("page-numbers", false, "stamp continuous page numbers (implied by the -page-number-* flags)")
+	pageNumberFormat := fs.String("page-number-format", "", "page number label; {page} and {total} expand to the page number and last page (default \""+merger.DefaultPageNumberFormat+"\")")
+	pageNumberPosition := fs.String("page-number-position", "", "page number position ("+strings.Join(merger.Positions, ", ")+") (default bc)")
+	pageNumberFont := fs.String("page-number-font", "", "page number font name (default Helvetica)")
+	pageNumberSize := fs.Int("page-number-size", 0, "page number font size in points (default 10)")
+	pageNumberColor := fs.String("page-number-color", "", "page number color as #RRGGBB")
+	pageNumberMargin := fs.Float64("page-number-margin", 0, "distance of the page number from the page edge in points (default 20)")
+	pageNumberStart := fs.Int("page-number-start", 0, "number of the first numbered page (default 1)")
+	pageNumberSkip := fs.Int("page-number-skip", 0, "number of leading pages left unnumbered")
+	skipFrontMatter := fs.Bool("page-number-skip-front", false, "leave the cover and table of contents unnumbered")
+	watermarkText := fs.String("watermark", "", "text watermark, e.g. DRAFT (%p and %P expand to page number and count)")
+	watermarkImage := fs.String("watermark-image", "", "path to a PNG or JPEG watermark, e.g. a logo")
+	stamp := fs.Bool("stamp", false, "draw watermarks over the page content instead of behind it")
+	watermarkFont := fs.String("watermark-font", "", "watermark font name (default Helvetica)")
+	watermarkSize := fs.Int("watermark-size", 0, "watermark font size in points (default scales to the page)")
+	watermarkColor := fs.String("watermark-color", "", "watermark color as #RRGGBB")
+	watermarkOpacity := fs.Float64("watermark-opacity", 0, "watermark opacity between 0 and 1 (default 1)")
+	watermarkRotation := fs.String("watermark-rotation", "", "watermark rotation in degrees (default follows the page diagonal)")
+	watermarkPosition := fs.String("watermark-position", "", "watermark position ("+strings.Join(merger.Positions, ", ")+")")
+	watermarkPages := fs.String("watermark-pages", "", "pages to watermark, e.g. 1-3,odd (default all)")
+	impose := fs.String("impose", "", "lay pages out on sheets ("+strings.Join(merger.ImpositionModes, ", ")+")")
+	imposePaper := fs.String("impose-paper", "", "sheet size for -impose, e.g. A4 or Letter (default A4)")
+	imposeLandscape := fs.Bool("impose-landscape", false, "turn the -impose sheets sideways")
+	imposeBorder := fs.Bool("impose-border", false, "frame every page on the -impose sheets")
+	imposeMargin := fs.Float64("impose-margin", 0, "space around every page on the -impose sheets in points")
+	printPreset := fs.String("print-preset", "", "print preset ("+strings.Join(pipeline.PrintPresets, ", ")+")")
+	noBackground := fs.Bool("no-background", false, "print web pages without background colors and images")
+	grayscale := fs.Bool("grayscale", false, "render every page in shades of gray")
+	stripLinks := fs.Bool("strip-links", false, "print links like plain text and remove them from the PDF")
+	attachSources := fs.Bool("attach-sources", false, "attach sources.txt listing every source")
+	attachSnapshots := fs.Bool("attach-snapshots", false, "attach the rendered HTML of every page")
+	var attachFiles []string
+	fs.Func("attach", "path to a file to attach to the PDF (repeatable)", func(v string) error {
+		attachFiles = append(attachFiles, v)
+		return nil
+	})
+	compression := fs.String("compression", merger.CompressionNone,
+		"output compression ("+strings.Join(merger.CompressionLevels(), ", ")+")")
+	imageDPI := fs.Int("image-dpi", 0, "resolution images are downsampled to when compressing (default depends on -compression)")
+	encrypt := fs.Bool("encrypt", false, "encrypt the PDF with AES-256 (implied by the password flags)")
+	userPassword := fs.String("user-password", "", "password required to open the encrypted PDF (visible in the process list, prefer -user-password-file or PDF_USER_PASSWORD)")
+	userPasswordFile := fs.String("user-password-file", "", "file holding the password required to open the encrypted PDF")
+	ownerPassword := fs.String("owner-password", "", "password that lifts all restrictions, random when empty (visible in the process list, prefer -owner-password-file or PDF_OWNER_PASSWORD)")
+	ownerPasswordFile := fs.String("owner-password-file", "", "file holding the password that lifts all restrictions")
+	allowPrint := fs.Bool("allow-print", false, "allow printing the encrypted PDF")
+	allowCopy := fs.Bool("allow-copy", false, "allow copying text and images from the encrypted PDF")
+	allowModify := fs.Bool("allow-modify", false, "allow modifying the encrypted PDF")
+	allowAnnotate := fs.Bool("allow-annotate", false, "allow annotating and filling forms of the encrypted PDF")
+	sign := fs.Bool("sign", false, "digitally sign the PDF with SIGN_CERT_FILE (implied by the -sign-* flags)")
+	signVisible := fs.Bool("sign-visible", false, "draw the signature on the page")
+	signPage := fs.Int("sign-page", 0, "page of the visible signature (default last page)")
+	signRect := fs.String("sign-rect", "", "box of the visible signature as x1,y1,x2,y2 in points (default bottom right)")
+	signReason := fs.String("sign-reason", "", "reason for signing")
+	signLocation := fs.String("sign-location", "", "location of signing")
+	signContact := fs.String("sign-contact", "", "contact information of the signer")
+	pdfa := fs.Bool("pdfa", false, "make the PDF conform to PDF/A-2b for archival (not with encryption)")
+	validation := fs.String("validation", pipeline.ValidationWarn, "check the PDF against the specification: off, warn or fail")
+	title := fs.String("title", "", "document title")
+	author := fs.String("author", cfg.PDFAuthor, "document author")
+	subject := fs.String("subject", "", "document subject")
+	keywords := fs.String("keywords", "", "comma-separated document keywords")
+	creator := fs.String("creator", cfg.PDFCreator, "application that created the content")
+	producer := fs.String("producer", cfg.PDFProducer, "application that produced the PDF")
+	language := fs.String("lang", cfg.PDFLanguage, "document language (defaults to -locale)")
+	properties := map[string]string{}
+	fs.Func("property", "custom document property as key=value (repeatable)", func(v string) error {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("expected key=value, got %q", v)
+		}
+		properties[key] = value
+		return nil
+	})
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: rapid_pdf [flags] <url|file.md>...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	urls := fs.Args()
+
+	// The environment only supplies passwords when encryption was asked for,
+	// so a password left in .env does not encrypt every run.
+	*encrypt = *encrypt || *userPassword != "" || *ownerPassword != "" ||
+		*userPasswordFile != "" || *ownerPasswordFile != ""
+
+	for _, secret := range []struct {
+		flag      string
+		value     *string
+		file, env string
+	}{
+		{"user-password", userPassword, *userPasswordFile, "PDF_USER_PASSWORD"},
+		{"owner-password", ownerPassword, *ownerPasswordFile, "PDF_OWNER_PASSWORD"},
+	} {
+		if *secret.value != "" && secret.file != "" {
+			fmt.Printf("\n❌ Error: -%s cannot be combined with -%s-file\n", secret.flag, secret.flag)
+			os.Exit(1)
+		}
+		if !*encrypt {
+			continue
+		}
+		password, err := readPassword(*secret.value, secret.file, secret.env)
+		if err != nil {
+			fmt.Printf("\n❌ Error: %v\n", err)
+			os.Exit(1)
+		}
+		*secret.value = password
+	}
+
+	if len(urls) == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
 	// Validate number of URLs.
 	if len(urls) > cfg.MaxURLs {
 		slog.Error("too many URLs provided",
@@ -103,14 +279,225 @@ func runCLI(cfg *config.Config, urls []string) {
 		os.Exit(1)
 	}
 
-	// Validate each URL format.
+	if !markdown.IsTheme(*theme) {
+		fmt.Printf("\n❌ Error: unknown theme %q. Available: %s\n", *theme, strings.Join(markdown.Themes(), ", "))
+		os.Exit(1)
+	}
+
+	if !merger.IsCompressionLevel(*compression) {
+		fmt.Printf("\n❌ Error: unknown compression %q. Available: %s\n", *compression, strings.Join(merger.CompressionLevels(), ", "))
+		os.Exit(1)
+	}
+
+	geo, err := parseGeolocation(*geolocation)
+	if err != nil {
+		fmt.Printf("\n❌ Error: invalid -geolocation: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := (merger.Metadata{Properties: properties}).Validate(); err != nil {
+		fmt.Printf("\n❌ Error: invalid -property: %v\n", err)
+		os.Exit(1)
+	}
+
+	watermarks, err := buildWatermarks(merger.Watermark{
+		OnTop:    *stamp,
+		FontName: *watermarkFont,
+		FontSize: *watermarkSize,
+		Color:    *watermarkColor,
+		Opacity:  *watermarkOpacity,
+		Position: *watermarkPosition,
+		Pages:    splitList(*watermarkPages),
+	}, *watermarkText, *watermarkImage, *watermarkRotation)
+	if err != nil {
+		fmt.Printf("\n❌ Error: invalid watermark: %v\n", err)
+		os.Exit(1)
+	}
+
+	var coverPage *converter.Cover
+	if *cover || *coverTitle != "" || *coverSubtitle != "" || *coverAuthor != "" || *coverDate != "" || *coverLogo != "" || *coverTemplate != "" {
+		coverPage, err = buildCover(*coverTitle, *coverSubtitle, *coverAuthor, *coverDate, *coverLogo, *coverTemplate)
+		if err != nil {
+			fmt.Printf("\n❌ Error: invalid cover: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var layout *merger.PageLayout
+	if *paperSize != "" || *landscape || *pageScale != "" || *autoRotate || *pageMargin != 0 {
+		layout = &merger.PageLayout{
+			PaperSize:  *paperSize,
+			Landscape:  *landscape,
+			Scale:      *pageScale,
+			AutoRotate: *autoRotate,
+			Margin:     *pageMargin,
+		}
+		if err := layout.Validate(); err != nil {
+			fmt.Printf("\n❌ Error: invalid page layout: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var separatorPages *pipeline.Separators
+	if *separators || *separatorTitles || *separatorTemplate != "" {
+		separatorPages = &pipeline.Separators{Titled: *separatorTitles}
+		if *separatorTemplate != "" {
+			tmpl, err := os.ReadFile(*separatorTemplate)
+			if err != nil {
+				fmt.Printf("\n❌ Error: failed to read separator template: %v\n", err)
+				os.Exit(1)
+			}
+			separatorPages.Template = string(tmpl)
+		}
+		if err := separatorPages.Validate(); err != nil {
+			fmt.Printf("\n❌ Error: invalid separators: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var numbering *merger.PageNumbering
+	if *pageNumbers || *pageNumberFormat != "" || *pageNumberPosition != "" || *pageNumberFont != "" || *pageNumberSize != 0 ||
+		*pageNumberColor != "" || *pageNumberMargin != 0 || *pageNumberStart != 0 || *pageNumberSkip != 0 || *skipFrontMatter {
+		numbering = &merger.PageNumbering{
+			Format:   *pageNumberFormat,
+			Position: *pageNumberPosition,
+			FontName: *pageNumberFont,
+			FontSize: *pageNumberSize,
+			Color:    *pageNumberColor,
+			Margin:   *pageNumberMargin,
+			Start:    *pageNumberStart,
+			Skip:     *pageNumberSkip,
+		}
+		if err := numbering.Validate(); err != nil {
+			fmt.Printf("\n❌ Error: invalid page numbers: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var imposition *merger.Imposition
+	if *impose != "" {
+		imposition = &merger.Imposition{
+			Mode:      *impose,
+			PaperSize: *imposePaper,
+			Landscape: *imposeLandscape,
+			Border:    *imposeBorder,
+			Margin:    *imposeMargin,
+		}
+		if err := imposition.Validate(); err != nil {
+			fmt.Printf("\n❌ Error: invalid imposition: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var printing *pipeline.Print
+	if *printPreset != "" || *noBackground || *grayscale || *stripLinks {
+		printing = &pipeline.Print{
+			Preset:         *printPreset,
+			OmitBackground: *noBackground,
+			Grayscale:      *grayscale,
+			StripLinks:     *stripLinks,
+		}
+		if err := printing.Validate(); err != nil {
+			fmt.Printf("\n❌ Error: invalid -print-preset: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var attachments *pipeline.Attachments
+	if *attachSources || *attachSnapshots || len(attachFiles) > 0 {
+		attachments = &pipeline.Attachments{Sources: *attachSources, Snapshots: *attachSnapshots}
+		for _, path := range attachFiles {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				fmt.Printf("\n❌ Error: cannot read attachment: %v\n", err)
+				os.Exit(1)
+			}
+			attachments.Files = append(attachments.Files, merger.Attachment{Name: filepath.Base(path), Data: data})
+		}
+	}
+
+	var signature *merger.Signature
+	var signer *merger.Signer
+	if *sign || *signVisible || *signPage != 0 || *signRect != "" || *signReason != "" || *signLocation != "" || *signContact != "" {
+		signature = &merger.Signature{
+			Reason:      *signReason,
+			Location:    *signLocation,
+			ContactInfo: *signContact,
+			Visible:     *signVisible || *signPage != 0 || *signRect != "",
+			Page:        *signPage,
+		}
+		if signature.Rect, err = parseRect(*signRect); err != nil {
+			fmt.Printf("\n❌ Error: invalid -sign-rect: %v\n", err)
+			os.Exit(1)
+		}
+		if err := signature.Validate(); err != nil {
+			fmt.Printf("\n❌ Error: invalid signature: %v\n", err)
+			os.Exit(1)
+		}
+		if *encrypt {
+			fmt.Println("\n❌ Error: signing cannot be combined with encryption")
+			os.Exit(1)
+		}
+		if !cfg.IsSigningConfigured() {
+			fmt.Println("\n❌ Error: signing requires SIGN_CERT_FILE in .env")
+			os.Exit(1)
+		}
+		if signer, err = loadSigner(cfg); err != nil {
+			fmt.Printf("\n❌ Error: cannot load signing certificate: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	checkValidationMode(*validation)
+	if *pdfa && *encrypt {
+		fmt.Println("\n❌ Error: -pdfa cannot be combined with encryption")
+		os.Exit(1)
+	}
+	if *pdfa && len(properties) > 0 {
+		fmt.Println("\n❌ Error: -pdfa cannot be combined with -property")
+		os.Exit(1)
+	}
+
+	var customCSS []byte
+	if *stylesheet != "" {
+		customCSS, err = os.ReadFile(*stylesheet)
+		if err != nil {
+			fmt.Printf("\n❌ Error: cannot read stylesheet: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Validate each URL format; Markdown files are rendered to HTML up front.
+	sources := make([]converter.Source, 0, len(urls))
 	for i, u := range urls {
+		if isMarkdownFile(u) {
+			src, err := os.ReadFile(u)
+			if err != nil {
+				fmt.Printf("\n❌ Error: cannot read Markdown file #%d: %v\n", i+1, err)
+				os.Exit(1)
+			}
+
+			html, err := markdown.Render(src, markdown.Options{
+				Theme:      *theme,
+				Stylesheet: string(customCSS),
+			})
+			if err != nil {
+				fmt.Printf("\n❌ Error: cannot render Markdown file #%d: %v\n", i+1, err)
+				os.Exit(1)
+			}
+
+			sources = append(sources, converter.Source{HTML: html})
+			continue
+		}
+
 		if !isValidURL(u) {
 			slog.Error("invalid URL", "index", i+1, "url", u)
 			fmt.Printf("\n❌ Error: invalid URL #%d: %s\n", i+1, u)
-			fmt.Println("   URLs must start with http:// or https://")
+			fmt.Println("   URLs must start with http:// or https://, or point to a .md file")
 			os.Exit(1)
 		}
+
+		sources = append(sources, converter.Source{URL: u})
 	}
 
 	slog.Info("configuration loaded",
@@ -118,6 +505,20 @@ func runCLI(cfg *config.Config, urls []string) {
 		"urls_provided", len(urls),
 	)
 
+	var encryption *merger.Encryption
+	if *encrypt {
+		encryption = &merger.Encryption{
+			UserPassword:  *userPassword,
+			OwnerPassword: *ownerPassword,
+			Permissions: merger.Permissions{
+				Print:    *allowPrint,
+				Copy:     *allowCopy,
+				Modify:   *allowModify,
+				Annotate: *allowAnnotate,
+			},
+		}
+	}
+
 	// Start conversion.
 	start := time.Now()
 	ctx := context.Background()
@@ -126,37 +527,484 @@ func runCLI(cfg *config.Config, urls []string) {
 	fmt.Printf("📄 Converting %d %s to PDF...\n", len(urls), pluralize(len(urls), "page", "pages"))
 	fmt.Println(strings.Repeat("─", 50))
 
-	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
-	waitDelay := time.Duration(cfg.PageLoadWaitSeconds) * time.Second
-	pdfFiles, err := converter.ConvertAll(ctx, urls, timeout, waitDelay)
-	if err != nil {
-		slog.Error("conversion failed", "error", err)
-		merger.Cleanup(pdfFiles) // Clean up any partial results.
-		fmt.Printf("\n❌ Conversion failed: %v\n", err)
-		os.Exit(1)
+	opts := pipeline.Options{
+		Render: converter.Options{
+			Timeout:        time.Duration(cfg.TimeoutSeconds) * time.Second,
+			WaitDelay:      time.Duration(cfg.PageLoadWaitSeconds) * time.Second,
+			SinglePage:     *singlePage,
+			Locale:         *locale,
+			TimezoneID:     *timezoneID,
+			AcceptLanguage: *acceptLanguage,
+			Geolocation:    geo,
+			SpillThreshold: cfg.SpillThreshold(),
+			// Downloaded PDFs are held to the same limit as uploaded ones
+			MaxDownloadBytes: cfg.MaxUploadBytes(),
+		},
+		Cover:           coverPage,
+		TOC:             *toc,
+		TOCTitle:        *tocTitle,
+		PageLayout:      layout,
+		Duplex:          *duplex,
+		Separators:      separatorPages,
+		PageNumbers:     numbering,
+		SkipFrontMatter: *skipFrontMatter,
+		Watermarks:      watermarks,
+		Imposition:      imposition,
+		Print:           printing,
+		Attachments:     attachments,
+		Compression:     *compression,
+		ImageDPI:        *imageDPI,
+		Encryption:      encryption,
+		PDFA:            *pdfa,
+		Signature:       signature,
+		Signer:          signer,
+		Validation:      *validation,
+		Metadata: merger.Metadata{
+			Title:      *title,
+			Author:     *author,
+			Subject:    *subject,
+			Keywords:   splitList(*keywords),
+			Creator:    *creator,
+			Producer:   *producer,
+			Language:   cmp.Or(*language, *locale),
+			Properties: properties,
+		},
 	}
 
-	fmt.Println(strings.Repeat("─", 50))
-	fmt.Printf("✅ All pages converted. Merging into %s...\n", defaultOutputFile)
+	if err := opts.Render.ValidateEmulation(); err != nil {
+		fmt.Printf("\n❌ Error: %v\n", err)
+		os.Exit(1)
+	}
 
-	// Merge all PDFs into one.
-	if err := merger.MergePDFs(pdfFiles, defaultOutputFile); err != nil {
-		slog.Error("merge failed", "error", err)
-		merger.Cleanup(pdfFiles)
-		fmt.Printf("\n❌ Merge failed: %v\n", err)
+	// Convert all sources and merge them into one PDF.
+	out, report, err := pipeline.Generate(ctx, sources, opts)
+	if errors.Is(err, pipeline.ErrInvalidOutput) {
+		fmt.Println("\n❌ The generated PDF failed validation:")
+		printValidation(report)
+		os.Exit(1)
+	}
+	if err != nil {
+		slog.Error("generation failed", "error", err)
+		fmt.Printf("\n❌ Generation failed: %v\n", err)
+		os.Exit(1)
+	}
+	err = writeOutput(defaultOutputFile, out)
+	out.Close()
+	if err != nil {
+		fmt.Printf("\n❌ %v\n", err)
 		os.Exit(1)
 	}
 
-	// Clean up temporary files.
-	merger.Cleanup(pdfFiles)
+	fmt.Println(strings.Repeat("─", 50))
 
 	elapsed := time.Since(start)
 	fmt.Println()
 	fmt.Printf("🎉 Done! PDF saved as: %s\n", defaultOutputFile)
+	if *compression != merger.CompressionNone {
+		fmt.Printf("🗜  Size: %s → %s\n", formatSize(report.SizeBefore), formatSize(report.SizeAfter))
+	}
+	printValidation(report)
 	fmt.Printf("⏱  Completed in %s\n", elapsed.Round(time.Millisecond))
 	fmt.Println()
 }
 
+// printValidation lists the validation problems and PDF/A checks of a
+// generate job.
+func printValidation(report pipeline.Report) {
+	for _, problem := range report.ValidationErrors {
+		fmt.Printf("⚠️  %s\n", problem)
+	}
+	for _, c := range report.Conformance {
+		if c.Passed {
+			fmt.Printf("✅ PDF/A %s\n", c.Name)
+		} else {
+			fmt.Printf("❌ PDF/A %s: %s\n", c.Name, c.Detail)
+		}
+	}
+}
+
+// checkValidationMode exits when mode is not one of the -validation modes.
+func checkValidationMode(mode string) {
+	switch mode {
+	case pipeline.ValidationOff, pipeline.ValidationWarn, pipeline.ValidationFail:
+	default:
+		fmt.Printf("\n❌ Error: invalid -validation %q: expected off, warn or fail\n", mode)
+		os.Exit(1)
+	}
+}
+
+// validateOutput checks a document written by a subcommand according to
+// mode and prints the problems found. It returns false when the document is
+// rejected; name describes it in the message.
+func validateOutput(rs io.ReadSeeker, name, mode string) bool {
+	var report pipeline.Report
+	if err := pipeline.Validate(rs, mode, "", false, &report); err != nil {
+		fmt.Printf("\n❌ %s failed validation:\n", name)
+		printValidation(report)
+		return false
+	}
+	printValidation(report)
+	return true
+}
+
+// validateFile checks the PDF at path like validateOutput.
+func validateFile(path, mode string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Printf("\n❌ Failed to read %s: %v\n", path, err)
+		return false
+	}
+	defer f.Close()
+	return validateOutput(f, path, mode)
+}
+
+// runSplit splits an existing PDF into several documents written to the
+// output directory.
+func runSplit(args []string) {
+	fs := flag.NewFlagSet("rapid_pdf split", flag.ExitOnError)
+	ranges := fs.String("ranges", "", "semicolon-separated page ranges, one document each, e.g. 1-3;4,6-8")
+	every := fs.Int("every", 0, "split into documents of this many pages")
+	bookmarks := fs.Bool("bookmarks", false, "split at every top-level bookmark")
+	outputDir := fs.String("output-dir", ".", "directory the parts are written to")
+	validation := fs.String("validation", pipeline.ValidationWarn, "check the output against the PDF specification: off, warn or fail")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: rapid_pdf split [-ranges r1;r2...|-every n|-bookmarks] [-output-dir dir] <file.pdf>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	checkValidationMode(*validation)
+
+	split := merger.Split{Every: *every, Bookmarks: *bookmarks}
+	for _, r := range strings.Split(*ranges, ";") {
+		if r = strings.TrimSpace(r); r != "" {
+			split.Ranges = append(split.Ranges, r)
+		}
+	}
+
+	parts, err := merger.SplitPDF(fs.Arg(0), *outputDir, split)
+	if err != nil {
+		fmt.Printf("\n❌ Split failed: %v\n", err)
+		os.Exit(1)
+	}
+	for _, part := range parts {
+		if !validateFile(part.Path, *validation) {
+			for _, part := range parts {
+				os.Remove(part.Path)
+			}
+			os.Exit(1)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("🎉 Done! Split into %d %s:\n", len(parts), pluralize(len(parts), "file", "files"))
+	for _, part := range parts {
+		if part.Title != "" {
+			fmt.Printf("   %s (pages %s, %s)\n", part.Path, part.Pages, part.Title)
+		} else {
+			fmt.Printf("   %s (pages %s)\n", part.Path, part.Pages)
+		}
+	}
+	fmt.Println()
+}
+
+// runExtract copies a selection of pages of an existing PDF into a new
+// document.
+func runExtract(args []string) {
+	fs := flag.NewFlagSet("rapid_pdf extract", flag.ExitOnError)
+	pages := fs.String("pages", "", "pages to keep, e.g. 1-3,7,odd")
+	output := fs.String("output", defaultOutputFile, "path of the extracted PDF")
+	validation := fs.String("validation", pipeline.ValidationWarn, "check the output against the PDF specification: off, warn or fail")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: rapid_pdf extract -pages selection [-output file.pdf] <file.pdf>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || *pages == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+	checkValidationMode(*validation)
+
+	if err := merger.ExtractPages(fs.Arg(0), *output, splitList(*pages)); err != nil {
+		fmt.Printf("\n❌ Extraction failed: %v\n", err)
+		os.Exit(1)
+	}
+	if !validateFile(*output, *validation) {
+		os.Remove(*output)
+		os.Exit(1)
+	}
+
+	fmt.Println()
+	fmt.Printf("🎉 Done! Pages saved as: %s\n", *output)
+	fmt.Println()
+}
+
+// runFill fills a PDF form with values read from a JSON file, or lists its
+// fields.
+func runFill(args []string) {
+	fs := flag.NewFlagSet("rapid_pdf fill", flag.ExitOnError)
+	data := fs.String("data", "", "path to a JSON object mapping field names to values")
+	flatten := fs.Bool("flatten", false, "draw the fields into the pages and remove the form")
+	list := fs.Bool("list", false, "list the fields of the form instead of filling it")
+	output := fs.String("output", defaultOutputFile, "path of the filled PDF")
+	validation := fs.String("validation", pipeline.ValidationWarn, "check the output against the PDF specification: off, warn or fail")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: rapid_pdf fill [-data values.json] [-flatten] [-output file.pdf] <form.pdf>")
+		fmt.Fprintln(fs.Output(), "       rapid_pdf fill -list <form.pdf>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || (!*list && *data == "" && !*flatten) {
+		fs.Usage()
+		os.Exit(1)
+	}
+	checkValidationMode(*validation)
+
+	in, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Printf("\n❌ Failed to open form: %v\n", err)
+		os.Exit(1)
+	}
+	defer in.Close()
+
+	if *list {
+		fields, err := merger.FormFields(in)
+		if err != nil {
+			fmt.Printf("\n❌ Listing fields failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println()
+		fmt.Printf("📝 %d form %s:\n", len(fields), pluralize(len(fields), "field", "fields"))
+		for _, field := range fields {
+			name := field.Name
+			if name == "" {
+				name = "#" + field.ID
+			}
+			line := fmt.Sprintf("   %s (%s) = %q", name, field.Type, field.Value)
+			if len(field.Options) > 0 {
+				line += " [" + strings.Join(field.Options, ", ") + "]"
+			}
+			if field.Locked {
+				line += " locked"
+			}
+			fmt.Println(line)
+		}
+		fmt.Println()
+		return
+	}
+
+	var values map[string]any
+	if *data != "" {
+		raw, err := os.ReadFile(*data)
+		if err != nil {
+			fmt.Printf("\n❌ Failed to read field values: %v\n", err)
+			os.Exit(1)
+		}
+		if err := json.Unmarshal(raw, &values); err != nil {
+			fmt.Printf("\n❌ Invalid field values: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	out := spool.New(spool.DefaultThreshold)
+	defer out.Close()
+
+	if err := merger.FillForm(in, out, values, *flatten); err != nil {
+		fmt.Printf("\n❌ Form fill failed: %v\n", err)
+		os.Exit(1)
+	}
+	if !validateOutput(out.Reader(), "The filled form", *validation) {
+		os.Exit(1)
+	}
+	if err := writeOutput(*output, out); err != nil {
+		fmt.Printf("\n❌ Failed to write output: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println()
+	fmt.Printf("🎉 Done! Filled form saved as: %s\n", *output)
+	fmt.Println()
+}
+
+// runImpose lays the pages of an existing PDF out on sheets, or reorders
+// them into a booklet.
+func runImpose(args []string) {
+	fs := flag.NewFlagSet("rapid_pdf impose", flag.ExitOnError)
+	mode := fs.String("mode", "", "how pages are laid out ("+strings.Join(merger.ImpositionModes, ", ")+")")
+	paper := fs.String("paper", "", "sheet size, e.g. A4 or Letter (default A4)")
+	landscape := fs.Bool("landscape", false, "turn the sheets sideways")
+	border := fs.Bool("border", false, "frame every page on the sheet")
+	margin := fs.Float64("margin", 0, "space around every page on the sheet in points")
+	output := fs.String("output", defaultOutputFile, "path of the imposed PDF")
+	validation := fs.String("validation", pipeline.ValidationWarn, "check the output against the PDF specification: off, warn or fail")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: rapid_pdf impose -mode 2-up|4-up|9-up|booklet [-paper size] [-landscape] [-output file.pdf] <file.pdf>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || *mode == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+	checkValidationMode(*validation)
+
+	in, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Printf("\n❌ Failed to open PDF: %v\n", err)
+		os.Exit(1)
+	}
+	defer in.Close()
+
+	out := spool.New(spool.DefaultThreshold)
+	defer out.Close()
+
+	imp := merger.Imposition{
+		Mode:      *mode,
+		PaperSize: *paper,
+		Landscape: *landscape,
+		Border:    *border,
+		Margin:    *margin,
+	}
+	if err := merger.Impose(in, out, imp); err != nil {
+		fmt.Printf("\n❌ Imposition failed: %v\n", err)
+		os.Exit(1)
+	}
+	if !validateOutput(out.Reader(), "The imposed PDF", *validation) {
+		os.Exit(1)
+	}
+	if err := writeOutput(*output, out); err != nil {
+		fmt.Printf("\n❌ Failed to write output: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println()
+	fmt.Printf("🎉 Done! Imposed PDF saved as: %s\n", *output)
+	fmt.Println()
+}
+
+// runInspect describes an existing PDF as JSON, written to stdout or to the
+// output file.
+func runInspect(args []string) {
+	fs := flag.NewFlagSet("rapid_pdf inspect", flag.ExitOnError)
+	password := fs.String("password", "", "password of an encrypted PDF")
+	output := fs.String("output", "", "path of the JSON report (default stdout)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: rapid_pdf inspect [-password pw] [-output report.json] <file.pdf>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Printf("\n❌ Failed to open PDF: %v\n", err)
+		os.Exit(1)
+	}
+	in, err := merger.Inspect(f, *password)
+	f.Close()
+	if err != nil {
+		fmt.Printf("\n❌ Inspection failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	report, err := json.MarshalIndent(api.NewInspection(in), "", "  ")
+	if err != nil {
+		fmt.Printf("\n❌ Inspection failed: %v\n", err)
+		os.Exit(1)
+	}
+	report = append(report, '\n')
+
+	if *output == "" {
+		os.Stdout.Write(report)
+		return
+	}
+	if err := os.WriteFile(*output, report, 0644); err != nil {
+		fmt.Printf("\n❌ Failed to write report: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println()
+	fmt.Printf("🎉 Done! Report saved as: %s\n", *output)
+	fmt.Println()
+}
+
+// buildCover assembles the cover page from the CLI flags, reading the logo
+// and the custom template from disk.
+func buildCover(title, subtitle, author, date, logoPath, templatePath string) (*converter.Cover, error) {
+	cover := &converter.Cover{
+		Title:    title,
+		Subtitle: subtitle,
+		Author:   author,
+		Date:     date,
+	}
+
+	if logoPath != "" {
+		logo, err := os.ReadFile(logoPath)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read logo: %w", err)
+		}
+		cover.Logo = logo
+	}
+
+	if templatePath != "" {
+		tmpl, err := os.ReadFile(templatePath)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read template: %w", err)
+		}
+		cover.Template = string(tmpl)
+	} else if title == "" {
+		return nil, fmt.Errorf("-cover-title is required without -cover-template")
+	}
+
+	if err := cover.Validate(); err != nil {
+		return nil, err
+	}
+	return cover, nil
+}
+
+// loadSigner loads the signing certificate from the configuration. It
+// returns nil when signing is not configured.
+func loadSigner(cfg *config.Config) (*merger.Signer, error) {
+	if !cfg.IsSigningConfigured() {
+		return nil, nil
+	}
+	return merger.LoadSigner(cfg.SignCertFile, cfg.SignKeyFile, cfg.SignCertPassword, cfg.SignTSAURL)
+}
+
+// parseRect parses a box given as "x1,y1,x2,y2" in points. An empty value
+// returns the zero box.
+func parseRect(value string) ([4]float64, error) {
+	var rect [4]float64
+	if value == "" {
+		return rect, nil
+	}
+
+	parts := strings.Split(value, ",")
+	if len(parts) != len(rect) {
+		return rect, fmt.Errorf("expected x1,y1,x2,y2, got %q", value)
+	}
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return rect, fmt.Errorf("%q is not a number", part)
+		}
+		rect[i] = v
+	}
+	return rect, nil
+}
+
 // isValidURL checks if the given string is a valid HTTP/HTTPS URL.
 func isValidURL(rawURL string) bool {
 	u, err := url.Parse(rawURL)
@@ -166,6 +1014,137 @@ func isValidURL(rawURL string) bool {
 	return u.Scheme == "http" || u.Scheme == "https"
 }
 
+// parseGeolocation parses a "lat,lon[,accuracy]" flag value. An empty value
+// disables geolocation emulation.
+func parseGeolocation(value string) (*converter.Geolocation, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(value, ",")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("expected lat,lon[,accuracy], got %q", value)
+	}
+
+	coords := make([]float64, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", p)
+		}
+		coords[i] = v
+	}
+
+	geo := &converter.Geolocation{Latitude: coords[0], Longitude: coords[1]}
+	if len(coords) == 3 {
+		geo.Accuracy = coords[2]
+	}
+
+	if geo.Latitude < -90 || geo.Latitude > 90 || geo.Longitude < -180 || geo.Longitude > 180 || geo.Accuracy < 0 {
+		return nil, fmt.Errorf("coordinates out of range: %q", value)
+	}
+
+	return geo, nil
+}
+
+// buildWatermarks returns the text and image watermarks requested on the
+// command line, both sharing the style of base.
+func buildWatermarks(base merger.Watermark, text, imagePath, rotation string) ([]merger.Watermark, error) {
+	if rotation != "" {
+		r, err := strconv.ParseFloat(rotation, 64)
+		if err != nil {
+			return nil, fmt.Errorf("rotation %q is not a number", rotation)
+		}
+		base.Rotation = &r
+	}
+
+	var marks []merger.Watermark
+	if text != "" {
+		mark := base
+		mark.Text = text
+		marks = append(marks, mark)
+	}
+	if imagePath != "" {
+		image, err := os.ReadFile(imagePath)
+		if err != nil {
+			return nil, err
+		}
+		mark := base
+		mark.Image = image
+		marks = append(marks, mark)
+	}
+
+	for _, mark := range marks {
+		if err := mark.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return marks, nil
+}
+
+// readPassword resolves a password given on the command line, in a file or
+// in the environment variable env, in that order of precedence. Files and
+// the environment keep the password out of the process list and the shell
+// history. A single trailing newline is stripped from the file.
+func readPassword(value, file, env string) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password file: %w", err)
+		}
+		password := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+		if password == "" {
+			return "", fmt.Errorf("password file %s is empty", file)
+		}
+		return password, nil
+	}
+	return os.Getenv(env), nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// isMarkdownFile reports whether the CLI argument names a Markdown file.
+func isMarkdownFile(arg string) bool {
+	switch strings.ToLower(filepath.Ext(arg)) {
+	case ".md", ".markdown":
+		return !isValidURL(arg)
+	}
+	return false
+}
+
+// writeOutput writes the generated document to path.
+func writeOutput(path string, out *spool.Buffer) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if _, err := io.Copy(f, out.Reader()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// formatSize formats a byte count in megabytes.
+func formatSize(bytes int64) string {
+	return fmt.Sprintf("%.2f MB", float64(bytes)/(1024*1024))
+}
+
 // pluralize returns singular or plural form based on count.
 func pluralize(count int, singular, plural string) string {
 	if count == 1 {